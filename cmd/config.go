@@ -2,34 +2,74 @@ package cmd
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-
 	"strings"
 
 	"github.com/Phantas0s/devdash/internal"
 	"github.com/adrg/xdg"
+	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
 const (
 	// keys
-	kQuit      = "C-c"
-	kHotReload = "C-r"
-	kEdit      = "C-e"
+	kQuit          = "C-c"
+	kHotReload     = "C-r"
+	kEdit          = "C-e"
+	kNextPage      = "C-n"
+	kPrevPage      = "C-p"
+	kNextWidget    = "<tab>"
+	kPrevWidget    = "<backspace>"
+	kScrollUp      = "<up>"
+	kScrollDown    = "<down>"
+	kZoom          = "z"
+	kHelp          = "?"
+	kPause         = "p"
+	kRefreshWidget = "r"
+	kAction        = "<enter>"
 )
 
+// keyNames which can be configured in the "keys" of the "general" section.
+var keyNames = []string{
+	"quit",
+	"hot_reload",
+	"edit",
+	"next_page",
+	"previous_page",
+	"next_widget",
+	"previous_widget",
+	"scroll_up",
+	"scroll_down",
+	"zoom",
+	"help",
+	"pause",
+	"refresh_widget",
+	"action",
+}
+
 type config struct {
 	General  General   `mapstructure:"general"`
 	Projects []Project `mapstructure:"projects"`
+	// includes are the files included by the config, and by the files it includes.
+	includes []string
 }
 
 type General struct {
 	Keys    map[string]string `mapstructure:"keys"`
 	Refresh int64             `mapstructure:"refresh"`
+	Timeout int64             `mapstructure:"timeout"`
 	Editor  string            `mapstructure:"editor"`
+	Workers int               `mapstructure:"workers"`
+	// Pages display each project on its own page, instead of one after the other.
+	Pages bool `mapstructure:"pages"`
+	// StatusBar display the state of the dashboard at the bottom of the terminal.
+	StatusBar bool `mapstructure:"status_bar"`
+	// Mouse enable the focus and the scrolling of the widgets with the mouse, and the tabs of the pages to be clicked.
+	Mouse bool `mapstructure:"mouse"`
+	// Concurrency limit the data fetched at the same time per service, for example "ga: 2".
+	Concurrency map[string]int `mapstructure:"concurrency"`
 }
 
 // RefreshTime return the duration before refreshing the data of all widgets, in seconds.
@@ -41,6 +81,24 @@ func (c config) RefreshTime() int64 {
 	return c.General.Refresh
 }
 
+// WorkerLimit return the maximum number of widgets fetching their data at the same time.
+func (c config) WorkerLimit() int {
+	if c.General.Workers == 0 {
+		return 10
+	}
+
+	return c.General.Workers
+}
+
+// TimeoutTime return the maximum duration to fetch the data of a widget, in seconds.
+func (c config) TimeoutTime() int64 {
+	if c.General.Timeout == 0 {
+		return 30
+	}
+
+	return c.General.Timeout
+}
+
 type Project struct {
 	Name        string                       `mapstructure:"name"`
 	NameOptions map[string]string            `mapstructure:"name_options"`
@@ -64,85 +122,9 @@ type Widgets struct {
 	Elements []internal.Widget `mapstructure:"elements"`
 }
 
-type Services struct {
-	GoogleAnalytics     GoogleAnalytics `mapstructure:"google_analytics"`
-	GoogleSearchConsole SearchConsole   `mapstructure:"google_search_console"`
-	Monitor             Monitor         `mapstructure:"monitor"`
-	Github              Github          `mapstructure:"github"`
-	TravisCI            TravisCI        `mapstructure:"travis"`
-	Feedly              Feedly          `mapstructure:"feedly"`
-	Git                 Git             `mapstructure:"git"`
-	RemoteHost          RemoteHost      `mapstructure:"remote_host"`
-	Localhost           RemoteHost      `mapstructure:"local_host"`
-}
-
-type GoogleAnalytics struct {
-	Keyfile string `mapstructure:"keyfile"`
-	ViewID  string `mapstructure:"view_id"`
-}
-
-type SearchConsole struct {
-	Keyfile string `mapstructure:"keyfile"`
-	Address string `mapstructure:"address"`
-}
-
-type Monitor struct {
-	Address string `mapstructure:"address"`
-}
-
-type Github struct {
-	Token      string `mapstructure:"token"`
-	Owner      string `mapstructure:"owner"`
-	Repository string `mapstructure:"repository"`
-}
-
-type TravisCI struct {
-	Token string `mapstructure:"token"`
-}
-
-type Feedly struct {
-	Address string `mapstructure:"address"`
-}
-
-type RemoteHost struct {
-	Username string `mapstructure:"username"`
-	Address  string `mapstructure:"address"`
-}
-
-type Git struct {
-	Path string `mapstructure:"path"`
-}
-
-func (g GoogleAnalytics) empty() bool {
-	return g == GoogleAnalytics{}
-}
-
-func (m Monitor) empty() bool {
-	return m == Monitor{}
-}
-func (s SearchConsole) empty() bool {
-	return s == SearchConsole{}
-}
-
-func (g Github) empty() bool {
-	return g == Github{}
-}
-
-func (t TravisCI) empty() bool {
-	return t == TravisCI{}
-}
-
-func (f Feedly) empty() bool {
-	return f == Feedly{}
-}
-
-func (g Git) empty() bool {
-	return g == Git{}
-}
-
-func (g RemoteHost) empty() bool {
-	return g == RemoteHost{}
-}
+// Services of a project, indexed by their configuration keys (for example "google_analytics").
+// Each service is created depending on the definition registered in the package internal.
+type Services map[string]map[string]string
 
 // OrderWidgets add the widgets to a three dimensional slice.
 // First dimension: index of the rows (ir or indexRows).
@@ -172,43 +154,85 @@ func dashPath() string {
 }
 
 // Map config and return it with the config path
-func mapConfig(cfgFile string) (config, string) {
-	if cfgFile == "" {
-		cfgFile = "default.yml"
-		createConfig(dashPath(), cfgFile, defaultConfig())
+func mapConfig(cfgFile string, vars []string) (config, string) {
+	cfg, used, err := loadConfig(cfgFile, vars)
+	if err != nil {
+		panic(err)
 	}
 
-	// viper.AddConfigPath(home)
-	viper.AddConfigPath(dashPath())
-	viper.AddConfigPath(".")
+	return cfg, used
+}
 
-	viper.SetConfigName(removeExt(cfgFile))
-	err := viper.ReadInConfig()
+// loadConfig like mapConfig, but return an error if the config can't be read or mapped.
+func loadConfig(cfgFile string, vars []string) (config, string, error) {
+	v, used, includes, err := readConfig(cfgFile, vars)
 	if err != nil {
-		tryReadFile(cfgFile)
+		return config{includes: includes.files}, used, err
 	}
 
+	cfg, err := unmarshalConfig(v, used, includes)
+	return cfg, used, err
+}
+
+// unmarshalConfig read with readConfig.
+func unmarshalConfig(v *viper.Viper, used string, includes includes) (config, error) {
 	var cfg config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		panic(err)
+	if err := v.Unmarshal(&cfg); err != nil {
+		return config{includes: includes.files}, errors.Wrapf(err, "could not map config %s", used)
 	}
+	cfg.includes = includes.files
 
-	prefix := "DEVDASH"
-	for k, _ := range cfg.Projects {
-		if cfg.Projects[k].Services.GoogleAnalytics.Keyfile == "" {
-			cfg.Projects[k].Services.GoogleAnalytics.Keyfile = os.Getenv(prefix + "_GA_KEYFILE")
-		}
+	return cfg, nil
+}
 
-		if cfg.Projects[k].Services.GoogleSearchConsole.Keyfile == "" {
-			cfg.Projects[k].Services.GoogleSearchConsole.Keyfile = os.Getenv(prefix + "_GSC_KEYFILE")
+// readConfig without mapping it, and return it with the config path and its includes.
+// The files included are merged, and the variables and the secrets of its values are resolved.
+// The vars are the values of the variables of the config, for example "repo=api".
+func readConfig(cfgFile string, vars []string) (*viper.Viper, string, includes, error) {
+	v, used, inc, err := openConfig(cfgFile)
+	if err == nil {
+		if err = resolveConfig(v, vars); err != nil {
+			err = errors.Wrapf(err, "could not resolve config %s", used)
 		}
+	}
 
-		if cfg.Projects[k].Services.Github.Token == "" {
-			cfg.Projects[k].Services.Github.Token = os.Getenv(prefix + "_GITHUB_TOKEN")
+	return v, used, inc, err
+}
+
+// openConfig and merge the files it includes, without resolving its values.
+func openConfig(cfgFile string) (*viper.Viper, string, includes, error) {
+	if cfgFile == "" {
+		cfgFile = "default.yml"
+		createConfig(dashPath(), cfgFile, defaultConfig())
+	}
+
+	v := viper.New()
+	// viper.AddConfigPath(home)
+	v.AddConfigPath(dashPath())
+	v.AddConfigPath(".")
+
+	v.SetConfigName(removeExt(cfgFile))
+	err := v.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		err = tryReadFile(v, cfgFile)
+	} else if err != nil {
+		err = errors.Wrapf(err, "could not read config %s", v.ConfigFileUsed())
+	}
+
+	// The config file is read directly if it's not found in the config paths.
+	used := v.ConfigFileUsed()
+	if used == "" {
+		used = cfgFile
+	}
+
+	var inc includes
+	if err == nil {
+		if inc, err = includeConfig(v, used); err != nil {
+			err = errors.Wrapf(err, "could not include the configs of %s", used)
 		}
 	}
 
-	return cfg, viper.ConfigFileUsed()
+	return v, used, inc, err
 }
 
 func removeExt(filepath string) string {
@@ -241,21 +265,23 @@ func createConfig(path string, filename string, template string) string {
 	return f
 }
 
-func tryReadFile(cfgFile string) {
+func tryReadFile(v *viper.Viper, cfgFile string) error {
 	if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
-		panic(fmt.Errorf("config %s doesnt exists", cfgFile))
+		return errors.Errorf("config %s doesnt exists", cfgFile)
 	}
 
 	f, err := ioutil.ReadFile(cfgFile)
 	if err != nil {
-		panic(fmt.Errorf("could not read file %s", cfgFile))
+		return errors.Errorf("could not read file %s", cfgFile)
 	}
 
-	viper.SetConfigType(strings.Trim(filepath.Ext(cfgFile), "."))
-	err = viper.ReadConfig(bytes.NewBuffer(f))
+	v.SetConfigType(strings.Trim(filepath.Ext(cfgFile), "."))
+	err = v.ReadConfig(bytes.NewBuffer(f))
 	if err != nil {
-		panic(fmt.Errorf("could not read config %s data", string(f)))
+		return errors.Wrapf(err, "could not read config %s", cfgFile)
 	}
+
+	return nil
 }
 
 // Keyboard events
@@ -307,3 +333,106 @@ projects:
                     title: " thevaluable.dev status "
                     color: yellow`
 }
+
+func (c config) KNextPage() string {
+	if ok := c.General.Keys["next_page"]; ok != "" {
+		return c.General.Keys["next_page"]
+	}
+
+	return kNextPage
+}
+
+func (c config) KPrevPage() string {
+	if ok := c.General.Keys["previous_page"]; ok != "" {
+		return c.General.Keys["previous_page"]
+	}
+
+	return kPrevPage
+}
+
+// pages of the dashboard: the projects displayed together on each page, and their names.
+// Every project is on the same page if the option "pages" is not set.
+func (c config) pages() (pages [][]Project, names []string) {
+	if !c.General.Pages {
+		return [][]Project{c.Projects}, []string{}
+	}
+
+	for _, p := range c.Projects {
+		pages = append(pages, []Project{p})
+		names = append(names, p.Name)
+	}
+
+	return pages, names
+}
+
+func (c config) KNextWidget() string {
+	if ok := c.General.Keys["next_widget"]; ok != "" {
+		return c.General.Keys["next_widget"]
+	}
+
+	return kNextWidget
+}
+
+func (c config) KPrevWidget() string {
+	if ok := c.General.Keys["previous_widget"]; ok != "" {
+		return c.General.Keys["previous_widget"]
+	}
+
+	return kPrevWidget
+}
+
+func (c config) KScrollUp() string {
+	if ok := c.General.Keys["scroll_up"]; ok != "" {
+		return c.General.Keys["scroll_up"]
+	}
+
+	return kScrollUp
+}
+
+func (c config) KScrollDown() string {
+	if ok := c.General.Keys["scroll_down"]; ok != "" {
+		return c.General.Keys["scroll_down"]
+	}
+
+	return kScrollDown
+}
+
+func (c config) KZoom() string {
+	if ok := c.General.Keys["zoom"]; ok != "" {
+		return c.General.Keys["zoom"]
+	}
+
+	return kZoom
+}
+
+func (c config) KHelp() string {
+	if ok := c.General.Keys["help"]; ok != "" {
+		return c.General.Keys["help"]
+	}
+
+	return kHelp
+}
+
+func (c config) KPause() string {
+	if ok := c.General.Keys["pause"]; ok != "" {
+		return c.General.Keys["pause"]
+	}
+
+	return kPause
+}
+
+func (c config) KRefreshWidget() string {
+	if ok := c.General.Keys["refresh_widget"]; ok != "" {
+		return c.General.Keys["refresh_widget"]
+	}
+
+	return kRefreshWidget
+}
+
+func (c config) KAction() string {
+	if ok := c.General.Keys["action"]; ok != "" {
+		return c.General.Keys["action"]
+	}
+
+	return kAction
+}
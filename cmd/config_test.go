@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -263,3 +266,142 @@ func Test_OrderWidgets(t *testing.T) {
 		})
 	}
 }
+
+func Test_pages(t *testing.T) {
+	projects := []Project{{Name: "first"}, {Name: "second"}}
+
+	testCases := []struct {
+		name          string
+		cfg           config
+		expectedPages [][]Project
+		expectedNames []string
+	}{
+		{
+			name:          "all projects on one page",
+			cfg:           config{Projects: projects},
+			expectedPages: [][]Project{projects},
+			expectedNames: []string{},
+		},
+		{
+			name:          "one project per page",
+			cfg:           config{General: General{Pages: true}, Projects: projects},
+			expectedPages: [][]Project{{projects[0]}, {projects[1]}},
+			expectedNames: []string{"first", "second"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualPages, actualNames := tc.cfg.pages()
+
+			if !reflect.DeepEqual(actualPages, tc.expectedPages) {
+				t.Errorf("Expected pages %v, actual %v", tc.expectedPages, actualPages)
+			}
+
+			if !reflect.DeepEqual(actualNames, tc.expectedNames) {
+				t.Errorf("Expected names %v, actual %v", tc.expectedNames, actualNames)
+			}
+		})
+	}
+}
+
+func Test_loadConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		vars     []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "valid config",
+			file:     "valid.yml",
+			content:  "projects:\n  - name: first\n",
+			expected: "first",
+		},
+		{
+			name:    "invalid yaml",
+			file:    "invalid.yml",
+			content: "projects:\n  - name: first\n   name: second\n",
+			wantErr: true,
+		},
+		{
+			name:    "wrong structure",
+			file:    "wrong.yml",
+			content: "projects: first\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			file:    "missing.yml",
+			wantErr: true,
+		},
+		{
+			name:     "environment variable in yaml",
+			file:     "env.yml",
+			content:  "projects:\n  - name: ${DEVDASH_TEST_NAME}\n",
+			expected: "from env",
+		},
+		{
+			name:     "environment variable in toml",
+			file:     "env.toml",
+			content:  "[[projects]]\nname = \"${DEVDASH_TEST_NAME}\"\n",
+			expected: "from env",
+		},
+		{
+			name:     "secret in json",
+			file:     "secret.json",
+			content:  `{"projects": [{"name": "cmd:echo from cmd"}]}`,
+			expected: "from cmd",
+		},
+		{
+			name:     "variable",
+			file:     "variables.yml",
+			content:  "variables:\n  repo:\n    description: Name of the repository\nprojects:\n  - name: ${var.repo}\n",
+			vars:     []string{"repo=api"},
+			expected: "api",
+		},
+		{
+			name:    "variable without value",
+			file:    "variables.yml",
+			content: "variables:\n  repo:\nprojects:\n  - name: ${var.repo}\n",
+			wantErr: true,
+		},
+		{
+			name:    "secret file missing",
+			file:    "secret.yml",
+			content: "projects:\n  - name: file:/devdash/missing\n",
+			wantErr: true,
+		},
+	}
+
+	os.Setenv("DEVDASH_TEST_NAME", "from env")
+	defer os.Unsetenv("DEVDASH_TEST_NAME")
+
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(dir, tc.file)
+			if tc.content != "" {
+				if err := ioutil.WriteFile(file, []byte(tc.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, _, err := loadConfig(file, tc.vars)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if err == nil && cfg.Projects[0].Name != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, cfg.Projects[0].Name)
+			}
+		})
+	}
+}
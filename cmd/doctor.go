@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/spf13/cobra"
+)
+
+func doctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor [config]",
+		Short: "Check the credentials and the connectivity of the services of a dashboard",
+		Long:  "Check every service configured in a dashboard: its credentials, its permissions and whether it can be reached. Each failed check comes with a hint to fix it. Exit with the status 1 if any check failed.",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := cfgName
+			if len(args) > 0 {
+				file = args[0]
+			}
+
+			cfg, used, err := loadConfig(file, cfgVars)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			projects := make([]map[string]map[string]string, 0, len(cfg.Projects))
+			for _, p := range cfg.Projects {
+				projects = append(projects, p.Services)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.TimeoutTime())*time.Second)
+			defer cancel()
+
+			results := internal.Diagnose(ctx, projects)
+			if failed := printDiagnoses(os.Stdout, results); failed > 0 {
+				fmt.Printf("%d service(s) of %s failed\n", failed, used)
+				cancel()
+				os.Exit(1)
+			}
+			fmt.Printf("Every service of %s is ready\n", used)
+		},
+	}
+}
+
+// printDiagnoses of the services, with a hint for each failed check. Return the number of services which failed.
+func printDiagnoses(out io.Writer, results []internal.ServiceDiagnosis) int {
+	failed := 0
+	for _, s := range results {
+		fmt.Fprintf(out, "%s (%s)\n", s.Service, s.Key)
+		if len(s.Diagnoses) == 0 {
+			fmt.Fprintln(out, "  SKIP  nothing to check")
+		}
+
+		for _, d := range s.Diagnoses {
+			if d.Err == nil {
+				fmt.Fprintf(out, "  PASS  %s\n", d.Check)
+				continue
+			}
+
+			fmt.Fprintf(out, "  FAIL  %s: %v\n", d.Check, d.Err)
+			if d.Hint != "" {
+				fmt.Fprintf(out, "        %s\n", d.Hint)
+			}
+		}
+
+		if s.Failed() {
+			failed++
+		}
+		fmt.Fprintln(out)
+	}
+
+	return failed
+}
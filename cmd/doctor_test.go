@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/pkg/errors"
+)
+
+func Test_printDiagnoses(t *testing.T) {
+	results := []internal.ServiceDiagnosis{
+		{
+			Service:   "Git",
+			Key:       "git",
+			Diagnoses: []internal.Diagnosis{{Check: "git is installed"}},
+		},
+		{
+			Service: "Remote host (web1)",
+			Key:     "remote_host:web1",
+			Diagnoses: []internal.Diagnosis{
+				{Check: "SSH agent has keys"},
+				{Check: "address is set", Err: errors.New("option address is empty"), Hint: "Set the option address."},
+			},
+		},
+	}
+
+	expected := `Git (git)
+  PASS  git is installed
+
+Remote host (web1) (remote_host:web1)
+  PASS  SSH agent has keys
+  FAIL  address is set: option address is empty
+        Set the option address.
+
+`
+
+	var out bytes.Buffer
+	failed := printDiagnoses(&out, results)
+
+	if out.String() != expected {
+		t.Errorf("Expected %v, actual %v", expected, out.String())
+	}
+
+	if failed != 1 {
+		t.Errorf("Expected %v, actual %v", 1, failed)
+	}
+}
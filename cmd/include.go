@@ -0,0 +1,343 @@
+package cmd
+
+// Includes and shared fragments of a config.
+// The section "include" of a config is a file or a list of files, relative to the config file or to the directory of
+// the dashboards. They're merged with the config in this order:
+// - The projects of the config come first, then the projects of each file included, in the order of the includes.
+// - The other sections (general, themes, rows and variables) are merged key by key: a key of the config wins over the same key
+//   of the files included, and a key of a file included wins over the same key of the files included before it.
+// - A file included multiple times is only merged the first time, and a file can't include itself.
+// The section "themes" holds the themes shared by every project: a theme of a project wins over the shared theme
+// with the same name, option by option.
+// The section "rows" holds named rows of widgets. A project use them by name, for example "- row: server_health".
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// includes of a config: the files included, and where the settings merged come from.
+type includes struct {
+	files []string
+	// origins of the settings, indexed by their path in the merged config.
+	origins map[string]origin
+}
+
+// origin of a setting: the file and the path where it's defined.
+type origin struct {
+	file string
+	path string
+}
+
+// locate a setting of the merged config: the file and the path where it's defined.
+// The settings without origin are in the config itself.
+func (inc includes) locate(config string, path string) (string, string) {
+	for p := path; p != ""; p = parentPath(p) {
+		if o, ok := inc.origins[p]; ok {
+			return o.file, o.path + strings.TrimPrefix(path, p)
+		}
+	}
+
+	return config, path
+}
+
+func parentPath(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return ""
+	}
+
+	return path[:i]
+}
+
+// includeConfig merge the files included by the config, then add the shared themes and the named rows to its projects.
+func includeConfig(v *viper.Viper, file string) (includes, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return includes{}, errors.Wrapf(err, "can't find config %s", file)
+	}
+
+	i := &includer{seen: map[string]bool{abs: true}, origins: map[string]origin{}}
+	settings, projects, err := i.merge(abs, v.AllSettings(), []string{abs})
+	if err != nil {
+		return includes{}, err
+	}
+
+	for k, o := range projects {
+		i.origins[join("projects", strconv.Itoa(k))] = o
+	}
+
+	if err := expandProjects(settings, i.origins); err != nil {
+		return includes{}, err
+	}
+
+	for _, k := range sortedKeys(settings) {
+		v.Set(k, settings[k])
+	}
+
+	// The settings of the config are located with the path given.
+	for k, o := range i.origins {
+		if o.file == abs {
+			i.origins[k] = origin{file: file, path: o.path}
+		}
+	}
+
+	return includes{files: i.files, origins: i.origins}, nil
+}
+
+// includer merge the files included, and keep track of them and of the origin of their settings.
+type includer struct {
+	seen    map[string]bool
+	files   []string
+	origins map[string]origin
+}
+
+// merge the settings of a config file with the files it includes. The stack is the chain of files including it.
+// Return the origins of the projects merged, in their order.
+func (i *includer) merge(
+	file string,
+	settings map[string]interface{},
+	stack []string,
+) (map[string]interface{}, []origin, error) {
+	names, err := includeNames(settings["include"])
+	if err != nil {
+		return nil, nil, settingError{path: "include", err: err}
+	}
+
+	projects, ok := settings["projects"].([]interface{})
+	if !ok && settings["projects"] != nil {
+		return nil, nil, settingError{path: "projects", err: errors.Errorf("projects of %s should be a list", file)}
+	}
+	projects = append([]interface{}{}, projects...)
+
+	origins := make([]origin, 0, len(projects))
+	for k := range projects {
+		origins = append(origins, origin{file: file, path: join("projects", strconv.Itoa(k))})
+	}
+
+	merged := map[string]interface{}{}
+	for _, name := range names {
+		f, err := findInclude(filepath.Dir(file), name)
+		if err != nil {
+			return nil, nil, settingError{path: "include", err: err}
+		}
+
+		if contains(stack, f) {
+			return nil, nil, errors.Errorf("config %s includes itself: %s", f, strings.Join(append(stack, f), " -> "))
+		}
+		if i.seen[f] {
+			continue
+		}
+		i.seen[f] = true
+		i.files = append(i.files, f)
+
+		s, err := readSettings(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s, o, err := i.merge(f, s, append(stack[:len(stack):len(stack)], f))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not include %s", f)
+		}
+
+		if p, ok := s["projects"].([]interface{}); ok {
+			projects = append(projects, p...)
+			origins = append(origins, o...)
+		}
+		delete(s, "projects")
+		merged = mergeSettings(merged, s)
+	}
+
+	own := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		if k != "include" && k != "projects" {
+			own[k] = v
+		}
+	}
+	merged = mergeSettings(merged, own)
+	i.record(file, "", own)
+
+	if settings["projects"] != nil || len(projects) > 0 {
+		merged["projects"] = projects
+	}
+
+	return merged, origins, nil
+}
+
+// record the origin of the keys of the mappings of the settings of a file, as they're merged.
+func (i *includer) record(file string, path string, settings map[string]interface{}) {
+	for k, v := range settings {
+		p := join(path, k)
+		i.origins[p] = origin{file: file, path: p}
+		if m, ok := settingsMap(v); ok {
+			i.record(file, p, m)
+		}
+	}
+}
+
+// includeNames of the section "include": a file or a list of files. Only the environment variables are interpolated.
+func includeNames(value interface{}) ([]string, error) {
+	var values []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	default:
+		return nil, errors.New("include should be a file or a list of files")
+	}
+
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("include should be a file or a list of files")
+		}
+
+		name, err := interpolate(s, nil)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// findInclude relative to the directory of the config including it, then to the directory of the dashboards.
+func findInclude(dir string, name string) (string, error) {
+	name = expandHome(name)
+	paths := []string{name}
+	if !filepath.IsAbs(name) {
+		paths = []string{filepath.Join(dir, name), filepath.Join(dashPath(), name)}
+	}
+
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return filepath.Abs(p)
+		}
+	}
+
+	return "", errors.Errorf("config %s included doesn't exist in %s", name, strings.Join(paths, " or "))
+}
+
+// readSettings of a config file, without its includes.
+func readSettings(file string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "could not read config %s", file)
+	}
+
+	return v.AllSettings(), nil
+}
+
+// mergeSettings of two configs. The mappings are merged key by key, and the other values of over win.
+func mergeSettings(base map[string]interface{}, over map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(base)+len(over))
+	for k, v := range base {
+		m[k] = v
+	}
+
+	for k, v := range over {
+		b, bok := settingsMap(m[k])
+		o, ook := settingsMap(v)
+		if bok && ook {
+			m[k] = mergeSettings(b, o)
+			continue
+		}
+		m[k] = v
+	}
+
+	return m
+}
+
+// expandProjects with the shared themes and the named rows, and record the origins of the settings added.
+func expandProjects(settings map[string]interface{}, origins map[string]origin) error {
+	themes, _ := settingsMap(settings["themes"])
+	rows, _ := settingsMap(settings["rows"])
+	projects, _ := settings["projects"].([]interface{})
+
+	for i, p := range projects {
+		project, ok := settingsMap(p)
+		if !ok {
+			continue
+		}
+		path := join("projects", strconv.Itoa(i))
+
+		if len(themes) > 0 {
+			if own, ok := settingsMap(project["themes"]); ok || project["themes"] == nil {
+				project["themes"] = mergeSettings(themes, own)
+				for name, theme := range themes {
+					options, _ := settingsMap(theme)
+					ownOptions, _ := settingsMap(own[name])
+					for k := range options {
+						if _, ok := ownOptions[k]; !ok {
+							origins[join(path, "themes", name, k)] = origins[join("themes", name, k)]
+						}
+					}
+				}
+			}
+		}
+
+		widgets, _ := project["widgets"].([]interface{})
+		expanded := make([]interface{}, 0, len(widgets))
+		for j, w := range widgets {
+			row, ok := settingsMap(w)
+			name, named := row["row"].(string)
+			if !ok || !named {
+				expanded = append(expanded, w)
+				continue
+			}
+
+			r, ok := rows[name]
+			if !ok {
+				return settingError{
+					path: join(path, "widgets", strconv.Itoa(j), "row"),
+					err:  errors.Errorf("row %s doesn't exist in the section rows", name),
+				}
+			}
+			row["row"] = r
+			expanded = append(expanded, row)
+			if o, ok := origins[join("rows", name)]; ok {
+				origins[join(path, "widgets", strconv.Itoa(j), "row")] = o
+			}
+		}
+		if widgets != nil {
+			project["widgets"] = expanded
+		}
+
+		projects[i] = project
+	}
+
+	return nil
+}
+
+// settingsMap copy a mapping of the settings, with keys of type string.
+func settingsMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = val
+		}
+		return m, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = val
+		}
+		return m, true
+	}
+
+	return nil, false
+}
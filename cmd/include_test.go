@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_includeConfig(t *testing.T) {
+	testCases := []struct {
+		name             string
+		files            map[string]string
+		expectedProjects []string
+		expectedThemes   map[string]map[string]string
+		expectedRows     int
+		expectedRefresh  int64
+		expectedTimeout  int64
+		expectedIncludes []string
+		wantErr          bool
+	}{
+		{
+			name: "projects, themes and rows",
+			files: map[string]string{
+				"main.yml": `include: [shared/themes.yml, shared/health.yml]
+general:
+  refresh: 10
+themes:
+  bar:
+    color: red
+projects:
+  - name: main
+    themes:
+      bar:
+        bar_color: blue
+    widgets:
+      - row: server_health
+      - row:
+          - col:
+              size: 12
+              elements:
+                - name: mon.box_availability
+`,
+				"shared/themes.yml": `general:
+  refresh: 600
+  timeout: 5
+themes:
+  bar:
+    color: green
+    border_color: yellow
+`,
+				"shared/health.yml": `rows:
+  server_health:
+    - col:
+        size: 6
+        elements:
+          - name: rh.box_uptime
+projects:
+  - name: health
+    widgets:
+      - row: server_health
+`,
+			},
+			expectedProjects: []string{"main", "health"},
+			expectedThemes:   map[string]map[string]string{"bar": {"color": "red", "border_color": "yellow", "bar_color": "blue"}},
+			expectedRows:     3,
+			expectedRefresh:  10,
+			expectedTimeout:  5,
+			expectedIncludes: []string{"shared/themes.yml", "shared/health.yml"},
+		},
+		{
+			name: "file included twice",
+			files: map[string]string{
+				"main.yml": "include: [a.yml, b.yml]\nprojects:\n  - name: main\n",
+				"a.yml":    "include: c.yml\nprojects:\n  - name: a\n",
+				"b.yml":    "include: c.yml\nprojects:\n  - name: b\n",
+				"c.yml":    "projects:\n  - name: c\n",
+			},
+			expectedProjects: []string{"main", "a", "c", "b"},
+			expectedIncludes: []string{"a.yml", "c.yml", "b.yml"},
+		},
+		{
+			name: "include in another format",
+			files: map[string]string{
+				"main.yml":    "include: shared.toml\n",
+				"shared.toml": "[[projects]]\nname = \"toml\"\n",
+			},
+			expectedProjects: []string{"toml"},
+			expectedIncludes: []string{"shared.toml"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.yml": "include: a.yml\n",
+				"a.yml":    "include: main.yml\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"main.yml": "include: missing.yml\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing row",
+			files: map[string]string{
+				"main.yml": "projects:\n  - name: main\n    widgets:\n      - row: missing\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "devdash")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			for name, content := range tc.files {
+				file := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, _, err := loadConfig(filepath.Join(dir, "main.yml"), nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			var projects []string
+			rows := 0
+			for _, p := range cfg.Projects {
+				projects = append(projects, p.Name)
+				for _, r := range p.Widgets {
+					if len(r.Row) > 0 {
+						rows++
+					}
+				}
+			}
+			if !reflect.DeepEqual(tc.expectedProjects, projects) {
+				t.Errorf("Expected %v, actual %v", tc.expectedProjects, projects)
+			}
+
+			if tc.expectedThemes != nil && !reflect.DeepEqual(tc.expectedThemes, cfg.Projects[0].Themes) {
+				t.Errorf("Expected %v, actual %v", tc.expectedThemes, cfg.Projects[0].Themes)
+			}
+
+			if tc.expectedRows > 0 && rows != tc.expectedRows {
+				t.Errorf("Expected %v, actual %v", tc.expectedRows, rows)
+			}
+
+			if cfg.General.Refresh != tc.expectedRefresh || cfg.General.Timeout != tc.expectedTimeout {
+				t.Errorf(
+					"Expected %v and %v, actual %v and %v",
+					tc.expectedRefresh,
+					tc.expectedTimeout,
+					cfg.General.Refresh,
+					cfg.General.Timeout,
+				)
+			}
+
+			var includes []string
+			for _, f := range tc.expectedIncludes {
+				includes = append(includes, filepath.Join(dir, f))
+			}
+			if !reflect.DeepEqual(includes, cfg.includes) {
+				t.Errorf("Expected %v, actual %v", includes, cfg.includes)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package cmd
+
+// Interpolation of the environment variables and resolution of the secrets in the values of a config.
+// "${VAR}" is replaced by the environment variable VAR (empty if it's not set), "${VAR:-default}" by the default
+// value if VAR is empty or not set, "${var.NAME}" by the variable NAME of the config, and "$${" is a literal "${".
+// A value starting with "file:" is replaced by the content of the file (for example "file:~/.secrets/gh"), and a
+// value starting with "cmd:" by the output of the command (for example "cmd:pass show github/token"), run only once.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	secretFile = "file:"
+	secretCmd  = "cmd:"
+	// varPrefix of the variables of the config, to tell them apart from the environment variables.
+	varPrefix = "var."
+)
+
+// secretTimeout of the secret commands, which might wait for a password prompt for example.
+var secretTimeout = 30 * time.Second
+
+// secrets resolved by the secret commands, indexed by command. A command is only run once: the secrets are kept
+// for the life of the process, and the reloads of the config don't prompt for them again.
+var secrets = struct {
+	sync.Mutex
+	values map[string]string
+}{values: map[string]string{}}
+
+// settingError of a value of the config, with the path of the value (for example "projects.0.services.github.token").
+type settingError struct {
+	path string
+	err  error
+}
+
+func (e settingError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+// resolveConfig replace the values of the config by their interpolated and resolved values.
+// The values are the values of the variables of the config, for example "repo=api".
+func resolveConfig(v *viper.Viper, values []string) error {
+	return setValues(v, values, resolveValue)
+}
+
+// checkValues of the config: their variables are interpolated, but their secrets aren't resolved. The secret files
+// need to exist, and the secret commands aren't run. Return the problems of every value.
+func checkValues(v *viper.Viper, values []string) ([]settingError, error) {
+	var problems []settingError
+	err := setValues(v, values, func(path string, value string, vars map[string]string) (string, error) {
+		s, err := interpolate(value, vars)
+		if err != nil {
+			problems = append(problems, settingError{path: path, err: err})
+			return value, nil
+		}
+		if err := checkSecret(s); err != nil {
+			problems = append(problems, settingError{path: path, err: err})
+		}
+		return s, nil
+	})
+
+	return problems, err
+}
+
+// valueResolver return the value of a setting, with the path of the setting and the variables of the config.
+type valueResolver func(path string, value string, vars map[string]string) (string, error)
+
+// setValues of the config to the values returned by the resolver.
+func setValues(v *viper.Viper, values []string, resolve valueResolver) error {
+	settings := v.AllSettings()
+	vars, err := configVariables(settings["variables"], values)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range sortedKeys(settings) {
+		if k == "variables" {
+			continue
+		}
+		r, err := resolveSettings(k, settings[k], func(path string, value string) (string, error) {
+			return resolve(path, value, vars)
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(k, r)
+	}
+
+	return nil
+}
+
+// resolveSettings resolve every string of the settings, at any depth.
+func resolveSettings(
+	path string,
+	value interface{},
+	resolve func(path string, value string) (string, error),
+) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return resolve(path, v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			r, err := resolveSettings(join(path, k), val, resolve)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = r
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			r, err := resolveSettings(join(path, fmt.Sprint(k)), val, resolve)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = r
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			r, err := resolveSettings(join(path, strconv.Itoa(i)), val, resolve)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = r
+		}
+		return l, nil
+	}
+
+	return value, nil
+}
+
+// resolveValue interpolate the environment variables of a value, then resolve it if it's a secret.
+func resolveValue(path string, value string, vars map[string]string) (string, error) {
+	v, err := interpolate(value, vars)
+	if err == nil {
+		v, err = resolveSecret(v)
+	}
+	if err != nil {
+		return "", settingError{path: path, err: err}
+	}
+
+	return v, nil
+}
+
+// interpolate the variables "${VAR}" and "${VAR:-default}" of a value with the environment variables, and the
+// variables "${var.NAME}" with the variables of the config.
+func interpolate(value string, vars map[string]string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(value, "${")
+		if i == -1 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+
+		// "$${" is escaped.
+		if i > 0 && value[i-1] == '$' {
+			b.WriteString(value[:i-1] + "${")
+			value = value[i+2:]
+			continue
+		}
+
+		end := strings.Index(value[i:], "}")
+		if end == -1 {
+			return "", errors.Errorf("variable not closed in %q", value)
+		}
+
+		name, def := value[i+2:i+end], ""
+		hasDefault := false
+		if d := strings.Index(name, ":-"); d != -1 {
+			name, def, hasDefault = name[:d], name[d+2:], true
+		}
+
+		var env string
+		if strings.HasPrefix(name, varPrefix) {
+			v, ok := vars[strings.ToLower(strings.TrimPrefix(name, varPrefix))]
+			if !ok {
+				return "", errors.Errorf("variable %s is not declared in the section variables", strings.TrimPrefix(name, varPrefix))
+			}
+			env = v
+		} else if isVarName(name) {
+			env = os.Getenv(name)
+		} else {
+			return "", errors.Errorf("variable %q is not a valid environment variable", name)
+		}
+
+		if env == "" && hasDefault {
+			env = def
+		}
+
+		b.WriteString(value[:i])
+		b.WriteString(env)
+		value = value[i+end+1:]
+	}
+}
+
+func isVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+// resolveSecret of a value: the content of a file or the output of a command. The spaces and the new lines around
+// the secret are removed. The other values are returned as they are.
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretFile):
+		file := expandHome(strings.TrimSpace(strings.TrimPrefix(value, secretFile)))
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read secret file %s", file)
+		}
+		return strings.TrimSpace(string(content)), nil
+	case strings.HasPrefix(value, secretCmd):
+		return runSecret(strings.TrimSpace(strings.TrimPrefix(value, secretCmd)))
+	}
+
+	return value, nil
+}
+
+// checkSecret of a value without resolving it: a secret file needs to exist. The secret commands aren't run.
+func checkSecret(value string) error {
+	if !strings.HasPrefix(value, secretFile) {
+		return nil
+	}
+
+	file := expandHome(strings.TrimSpace(strings.TrimPrefix(value, secretFile)))
+	if _, err := os.Stat(file); err != nil {
+		return errors.Wrapf(err, "could not read secret file %s", file)
+	}
+
+	return nil
+}
+
+// runSecret command, or return its output if it was already run.
+func runSecret(command string) (string, error) {
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	if s, ok := secrets.values[command]; ok {
+		return s, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	// The children of the shell can keep its output open after it's killed: don't wait for them.
+	done := make(chan error, 1)
+	go func() { done <- cmd.Run() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", errors.Wrapf(err, "secret command %s failed: %s", command, strings.TrimSpace(stderr.String()))
+		}
+	case <-ctx.Done():
+		return "", errors.Errorf("secret command %s timed out after %s", command, secretTimeout)
+	}
+
+	s := strings.TrimSpace(stdout.String())
+	secrets.values[command] = s
+
+	return s, nil
+}
+
+// expandHome replace the "~" at the beginning of a path by the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_interpolate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "no variable",
+			value:    "$DEVDASH_URL is not interpolated",
+			expected: "$DEVDASH_URL is not interpolated",
+		},
+		{
+			name:     "variables",
+			value:    "${DEVDASH_TEST_USER}@${DEVDASH_TEST_HOST}",
+			expected: "deploy@web1",
+		},
+		{
+			name:     "variable not set",
+			value:    "user: ${DEVDASH_TEST_UNSET}",
+			expected: "user: ",
+		},
+		{
+			name:     "default value",
+			value:    "${DEVDASH_TEST_UNSET:-localhost:22}",
+			expected: "localhost:22",
+		},
+		{
+			name:     "default value not used",
+			value:    "${DEVDASH_TEST_HOST:-localhost}",
+			expected: "web1",
+		},
+		{
+			name:     "escaped variable",
+			value:    "echo $${HOME}",
+			expected: "echo ${HOME}",
+		},
+		{
+			name:     "variable of the config",
+			value:    "${var.repo}-${DEVDASH_TEST_HOST}",
+			expected: "api-web1",
+		},
+		{
+			name:    "variable of the config not declared",
+			value:   "${var.host}",
+			wantErr: true,
+		},
+		{
+			name:    "variable not closed",
+			value:   "${DEVDASH_TEST_HOST",
+			wantErr: true,
+		},
+		{
+			name:    "invalid variable",
+			value:   "${DEVDASH TEST}",
+			wantErr: true,
+		},
+	}
+
+	os.Setenv("DEVDASH_TEST_USER", "deploy")
+	os.Setenv("DEVDASH_TEST_HOST", "web1")
+	defer os.Unsetenv("DEVDASH_TEST_USER")
+	defer os.Unsetenv("DEVDASH_TEST_HOST")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := interpolate(tc.value, map[string]string{"repo": "api"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_resolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := filepath.Join(dir, "gh")
+	if err := ioutil.WriteFile(secret, []byte("abc123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "not a secret",
+			value:    "https://thevaluable.dev",
+			expected: "https://thevaluable.dev",
+		},
+		{
+			name:     "file",
+			value:    "file:" + secret,
+			expected: "abc123",
+		},
+		{
+			name:    "file missing",
+			value:   "file:" + filepath.Join(dir, "missing"),
+			wantErr: true,
+		},
+		{
+			name:     "command",
+			value:    "cmd:cat " + secret + " | tr a-z A-Z",
+			expected: "ABC123",
+		},
+		{
+			name:    "command failing",
+			value:   "cmd:exit 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resolveSecret(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_runSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	timeout := secretTimeout
+	secretTimeout = 200 * time.Millisecond
+	defer func() { secretTimeout = timeout }()
+
+	runs := filepath.Join(dir, "runs")
+	testCases := []struct {
+		name     string
+		command  string
+		times    int
+		expected string
+		runs     int
+		wantErr  bool
+	}{
+		{
+			name:     "command run once",
+			command:  "echo run >> " + runs + " && echo abc123",
+			times:    3,
+			expected: "abc123",
+			runs:     1,
+		},
+		{
+			name:    "command failing run every time",
+			command: "echo run >> " + runs + " && exit 1",
+			times:   2,
+			runs:    2,
+			wantErr: true,
+		},
+		{
+			name:    "command timing out",
+			command: "sleep 5",
+			times:   1,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Remove(runs)
+
+			var actual string
+			var err error
+			for i := 0; i < tc.times; i++ {
+				actual, err = runSecret(tc.command)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+
+			content, _ := ioutil.ReadFile(runs)
+			if r := strings.Count(string(content), "run"); r != tc.runs {
+				t.Errorf("Expected %v, actual %v", tc.runs, r)
+			}
+		})
+	}
+}
@@ -3,19 +3,24 @@ package cmd
 // TODO see gocket to make the command right (with possibility to use env variables)
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/devdash/internal"
 	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Used for flags
 	cfgName string
+	cfgVars []string
 	logpath string
 	debug   bool
 
@@ -31,6 +36,7 @@ var (
 
 func init() {
 	rootCmd.Flags().StringVarP(&cfgName, "config", "c", "", "A valid dashboard configuration")
+	rootCmd.PersistentFlags().StringArrayVar(&cfgVars, "var", nil, "Value of a variable of the dashboard configuration (for example --var repo=api)")
 	// TODO logger
 	// rootCmd.Flags().StringVarP(&logpath, "logpath", "l", "", "Path for logging")
 	rootCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug Mode - doesn't display graph")
@@ -38,6 +44,9 @@ func init() {
 	rootCmd.AddCommand(versionCmd())
 	rootCmd.AddCommand(editCmd())
 	rootCmd.AddCommand(generateCmd())
+	rootCmd.AddCommand(validateCmd())
+	rootCmd.AddCommand(widgetsCmd())
+	rootCmd.AddCommand(doctorCmd())
 }
 
 func Execute() {
@@ -59,7 +68,7 @@ func run() {
 	defer tui.Close()
 
 	// Map dashboard config to a struct Config.
-	cfg, cfgFile := mapConfig(cfgName)
+	cfg, cfgFile := mapConfig(cfgName, cfgVars)
 	if debug {
 		fmt.Fprintf(os.Stdout, "Config file used: %s", cfgFile)
 	}
@@ -71,35 +80,150 @@ func run() {
 	tui.AddKHotReload(cfg.KHotReload(), hotReload)
 	tui.AddKQuit(cfg.KQuit())
 
-	// Passing a bool to this channel stop the automatic reload of the dashboard.
-	stopAutoReload := make(chan bool)
-	autoReload(cfg.RefreshTime(), stopAutoReload, hotReload)
+	// Passing a time.Time to this channel refresh the widgets which don't have their own refresh interval.
+	periodic := make(chan time.Time)
+	status := &reloadStatus{}
+	ticker := newRefreshTicker(cfg.RefreshTime(), periodic, status)
+	ticker.start()
 
 	editor := os.Getenv("EDITOR")
 	if cfg.General.Editor != "" {
 		editor = cfg.General.Editor
 	}
 
+	// The watcher is muted while the config is edited from the dashboard, which is reloaded when the editor is closed.
+	// It's nil if the config files can't be watched.
+	watcher, _ := newConfigWatcher()
+
 	// Add keystroke (managed by TUI) to edit the configuration in a CLI editor.
 	// Wrap edit config in lambda to defer the execution.
+	refresh := &autoRefresh{}
 	tui.AddKEdit(
 		cfg.KEdit(),
 		func() {
-			stopReload(stopAutoReload)
+			ticker.halt()
+			// The widgets are not refreshed over the editor.
+			restore := refresh.suspend()
+			if watcher != nil {
+				watcher.mute()
+			}
 			editDashboard(editor, cfgFile)
+			if watcher != nil {
+				watcher.unmute()
+			}
+			restore()
 			hotReload <- time.Now()
-			autoReload(cfg.RefreshTime(), stopAutoReload, hotReload)
+			if !refresh.isPaused() {
+				ticker.start()
+			}
 		},
 	)
 
+	// Keystrokes to pause the automatic refresh of the dashboard and its widgets, and to refresh the focused widget.
+	tui.AddKAction(cfg.KPause(), "Pause or resume the automatic refresh", func() {
+		refresh.toggle(ticker.halt, ticker.start)
+	})
+	tui.AddKAction(cfg.KRefreshWidget(), "Refresh the focused widget", func() {
+		refresh.widget(tui.Focused())
+	})
+
+	// The services are created once and reused by each build of the dashboard, till their configuration change.
+	services := internal.NewServiceStore()
+	defer services.Close()
+
+	// Keystrokes to move between the pages, if each project is on its own page.
+	page := &pager{}
+	tui.AddKAction(cfg.KNextPage(), "Next page", func() {
+		page.move(1)
+		hotReload <- time.Now()
+	})
+	tui.AddKAction(cfg.KPrevPage(), "Previous page", func() {
+		page.move(-1)
+		hotReload <- time.Now()
+	})
+
+	// Keystrokes to focus the widgets, scroll them and display them on the whole terminal.
+	tui.AddKAction(cfg.KNextWidget(), "Focus the next widget", func() { tui.Focus(1) })
+	tui.AddKAction(cfg.KPrevWidget(), "Focus the previous widget", func() { tui.Focus(-1) })
+	tui.AddKAction(cfg.KScrollUp(), "Scroll up the focused widget", func() { tui.Scroll(-1) })
+	tui.AddKAction(cfg.KScrollDown(), "Scroll down the focused widget", func() { tui.Scroll(1) })
+	tui.AddKAction(cfg.KZoom(), "Zoom the focused widget", func() { tui.Zoom() })
+	tui.AddKAction(cfg.KAction(), "Run the action of the selected row", tui.RunAction)
+	tui.AddKHelp(cfg.KHelp())
+
+	if cfg.General.Mouse && !debug {
+		tui.EnableMouse(func(tab int) {
+			if page.set(tab) {
+				hotReload <- time.Now()
+			}
+		})
+	}
+
+	// The responses cached for the widgets which don't exist anymore are never fetched again.
+	go func() {
+		_ = internal.NewCache(cacheDir()).Prune(internal.CacheRetention)
+	}()
+
 	// First display.
-	build(cfgName, tui)
+	// Cancelling the context interrupt the data fetching of the dashboard built.
+	ctx, cancel := context.WithCancel(context.Background())
+	refresh.use(build(ctx, cfg, tui, services, page))
+	status.refreshed(time.Now())
+
+	// Reload the dashboard each time the config file is saved.
+	if watcher != nil {
+		defer watcher.close()
+		if err := watcher.watch(append([]string{cfgFile}, cfg.includes...)); err != nil {
+			tui.SetConfigError(err)
+		}
+		go watcher.run(func() { hotReload <- time.Now() })
+	}
+
+	if cfg.General.StatusBar {
+		go func() {
+			// Update the countdown before the next reload.
+			for range time.Tick(time.Second) {
+				// Nothing is drawn over the editor.
+				if refresh.isSuspended() {
+					continue
+				}
+				tui.SetStatusBar(status.bar(cfgFile))
+			}
+		}()
+	}
+
+	// Automatic refresh of the widgets which don't have their own refresh interval.
+	go func() {
+		for t := range periodic {
+			refresh.periodic()
+			status.refreshed(t)
+		}
+	}()
 
-	// Automatic reload
+	// Reload the whole dashboard.
 	go func() {
 		for hr := range hotReload {
+			// The dashboard displayed stays as it is if the new config is invalid.
+			cfg, used, err := loadConfig(cfgName, cfgVars)
+			tui.SetConfigError(err)
+			if err != nil {
+				continue
+			}
+			// The refresh interval of the dashboard can change with the config.
+			ticker.reset(cfg.RefreshTime())
+
+			// The files included can change with the config.
+			if watcher != nil {
+				if err := watcher.watch(append([]string{used}, cfg.includes...)); err != nil {
+					tui.SetConfigError(err)
+				}
+			}
+
+			cancel()
 			tui.HotReload()
-			build(cfgName, tui)
+			ctx, cancel = context.WithCancel(context.Background())
+			refresh.use(build(ctx, cfg, tui, services, page))
+			status.refreshed(hr)
 			if debug {
 				fmt.Println("Last reload: " + hr.Format("2006-01-02 15:04:05"))
 			}
@@ -109,122 +233,159 @@ func run() {
 	tui.Loop()
 }
 
-func autoReload(refresh int64, stopAutoReload <-chan bool, hotReload chan<- time.Time) {
+// refreshTicker send a tick at the refresh interval of the dashboard, till it's halted.
+type refreshTicker struct {
+	mu       sync.Mutex
+	interval time.Duration
+	tick     chan<- time.Time
+	status   *reloadStatus
+	// stop the ticks sent. Nil if the ticker is halted.
+	stop chan struct{}
+	// ticks at an interval, with the function to stop them.
+	ticks func(d time.Duration) (<-chan time.Time, func())
+}
+
+func newRefreshTicker(refresh int64, tick chan<- time.Time, status *reloadStatus) *refreshTicker {
+	return &refreshTicker{
+		interval: time.Duration(refresh) * time.Second,
+		tick:     tick,
+		status:   status,
+		ticks: func(d time.Duration) (<-chan time.Time, func()) {
+			t := time.NewTicker(d)
+			return t.C, t.Stop
+		},
+	}
+}
+
+// start to send the ticks, if the ticker is halted.
+func (r *refreshTicker) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop == nil {
+		r.run()
+	}
+}
+
+// halt the ticks, even if a tick is waiting to be received.
+func (r *refreshTicker) halt() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+		r.status.scheduled(time.Time{})
+	}
+}
+
+// reset the refresh interval, in seconds. The ticker is restarted with the new interval if it's not halted.
+func (r *refreshTicker) reset(refresh int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interval = time.Duration(refresh) * time.Second
+	if r.stop != nil {
+		close(r.stop)
+		r.run()
+	}
+}
+
+// run the ticks in the background. The lock needs to be held.
+func (r *refreshTicker) run() {
+	stop, interval := make(chan struct{}), r.interval
+	r.stop = stop
+	r.status.scheduled(time.Now().Add(interval))
+
 	go func() {
-		ticker := time.NewTicker(time.Duration(refresh) * time.Second)
-		defer ticker.Stop()
+		ticks, stopTicks := r.ticks(interval)
+		defer stopTicks()
 		for {
 			select {
-			case <-stopAutoReload:
+			case <-stop:
 				return
-			case tick := <-ticker.C:
-				hotReload <- tick
+			case tick := <-ticks:
+				r.scheduled(stop, tick.Add(interval))
+				// The ticker can be halted while the previous tick is still handled.
+				select {
+				case r.tick <- tick:
+				case <-stop:
+					return
+				}
 			}
 		}
 	}()
 }
 
-func stopReload(stopAutoReload chan<- bool) {
-	stopAutoReload <- true
-}
+// scheduled the next tick, if the ticks are not stopped in the meantime.
+func (r *refreshTicker) scheduled(stop chan struct{}, next time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-// build every services present in the configuration
-func build(file string, tui *internal.Tui) {
-	cfg, _ := mapConfig(file)
-	for _, p := range cfg.Projects {
-		rows, sizes := p.OrderWidgets()
-		project := internal.NewProject(p.Name, p.NameOptions, rows, sizes, p.Themes, tui)
-
-		gaService := p.Services.GoogleAnalytics
-		if !gaService.empty() {
-			gaWidget, err := internal.NewGaWidget(gaService.Keyfile, gaService.ViewID)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGa(gaWidget)
-			}
-		}
+	if r.stop == stop {
+		r.status.scheduled(next)
+	}
+}
 
-		gscService := p.Services.GoogleSearchConsole
-		if !gscService.empty() {
-			gscWidget, err := internal.NewGscWidget(gscService.Keyfile, gscService.Address)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGoogleSearchConsole(gscWidget)
-			}
-		}
+// build every services present in the configuration, or reuse them from the store if their configuration didn't change.
+// Only the projects of the current page are built.
+// The widgets are fetched and refreshed till the context is done, by the scheduler returned.
+func build(ctx context.Context, cfg config, tui *internal.Tui, store *internal.ServiceStore, page *pager) *internal.Scheduler {
+	scheduler := internal.NewScheduler(ctx, tui, cfg.WorkerLimit(), cfg.General.Concurrency)
+	cache := internal.NewCache(cacheDir())
+
+	pages, names := cfg.pages()
+	current := page.current(len(pages))
+	if len(names) > 1 && !debug {
+		tui.AddTabBar(names, current)
+	}
 
-		monService := p.Services.Monitor
-		if !monService.empty() {
-			monWidget, err := internal.NewMonitorWidget(monService.Address)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithMonitor(monWidget)
-			}
-		}
+	projects := []Project{}
+	if len(pages) > 0 {
+		projects = pages[current]
+	}
 
-		githubService := p.Services.Github
-		if !githubService.empty() {
-			githubWidget, err := internal.NewGithubWidget(
-				githubService.Token,
-				githubService.Owner,
-				githubService.Repository,
-			)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGithub(githubWidget)
-			}
-		}
+	for _, p := range projects {
+		rows, sizes := p.OrderWidgets()
+		project := internal.NewProject(p.Name, p.NameOptions, rows, sizes, p.Themes, tui)
 
-		travisService := p.Services.TravisCI
-		if !travisService.empty() {
-			travisWidget := internal.NewTravisCIWidget(travisService.Token)
-			project.WithTravisCI(travisWidget)
+		services, errs := store.Services(p.Services)
+		for _, err := range errs {
+			tui.AddCell(internal.DisplayError(tui, err))
 		}
+		project.WithServices(services)
+		project.WithTimeout(time.Duration(cfg.TimeoutTime()) * time.Second)
+		project.WithCache(cache)
 
-		feedlyService := p.Services.Feedly
-		if !feedlyService.empty() {
-			feedlyWidget := internal.NewFeedlyWidget(feedlyService.Address)
-			project.WithFeedly(feedlyWidget)
+		if !debug {
+			project.Render()
+			project.ScheduleRefresh(scheduler)
 		}
 
-		gitService := p.Services.Git
-		if !gitService.empty() {
-			gitWidget := internal.NewGitWidget(gitService.Path)
-			project.WithGit(gitWidget)
+		project.CreateWidgets(scheduler)
+	}
+	// Close the services and drop the last draws of the widgets which are not in the configuration anymore.
+	// The ones of the other pages are kept.
+	for k, ps := range pages {
+		if k == current {
+			continue
 		}
-
-		remoteHostService := p.Services.RemoteHost
-		if !remoteHostService.empty() {
-			remoteHostWidget, err := internal.NewHostWidget(
-				remoteHostService.Username,
-				remoteHostService.Address,
-			)
-			if err != nil {
-				fmt.Println(err)
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithRemoteHost(remoteHostWidget)
-			}
+		for _, p := range ps {
+			store.Keep(p.Services)
+			rows, sizes := p.OrderWidgets()
+			tui.KeepWidgets(internal.NewProject(p.Name, p.NameOptions, rows, sizes, p.Themes, tui).WidgetKeys()...)
 		}
+	}
+	store.Release()
+	tui.ReleaseWidgets()
+	scheduler.Start()
 
-		localhost, err := internal.NewHostWidget("localhost", "localhost")
-		if err != nil {
-			fmt.Println(err)
-			internal.DisplayError(tui, err)()
-		}
-		project.WithLocalhost(localhost)
+	return scheduler
+}
 
-		// TODO choice between concurency and non concurency
-		// renderFuncs := project.CreateNonConcWidgets()
-		renderFuncs := project.CreateWidgets()
-		if !debug {
-			project.Render(renderFuncs)
-		}
-	}
+// cacheDir where the responses of the services are cached.
+func cacheDir() string {
+	return filepath.Join(xdg.CacheHome, "devdash")
 }
 
 // TODO - Wrap logger. If logger nil, drop the message
@@ -240,3 +401,171 @@ func InitLoggerFile(logpath string) *log.Logger {
 
 	return log.New(file, "", 0)
 }
+
+// pager keep the page of the dashboard displayed.
+type pager struct {
+	mu   sync.Mutex
+	page int
+}
+
+// move from the current page to another one. A negative offset move to the previous pages.
+func (p *pager) move(offset int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.page += offset
+}
+
+// set the current page. Return false if it's already the current one.
+func (p *pager) set(page int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.page == page {
+		return false
+	}
+	p.page = page
+
+	return true
+}
+
+// current page, for a dashboard with the number of pages given. Moving after the last page goes back to the first one.
+func (p *pager) current(pages int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pages == 0 {
+		return 0
+	}
+	p.page = ((p.page % pages) + pages) % pages
+
+	return p.page
+}
+
+// reloadStatus of the dashboard, displayed in the status bar.
+type reloadStatus struct {
+	mu   sync.Mutex
+	last time.Time
+	// next automatic refresh. The automatic refresh is paused if zero.
+	next time.Time
+}
+
+func (r *reloadStatus) refreshed(at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last = at
+}
+
+func (r *reloadStatus) scheduled(next time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next = next
+}
+
+// bar to display for the dashboard of the config file given.
+func (r *reloadStatus) bar(cfgFile string) internal.StatusBar {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return internal.StatusBar{
+		Config:      cfgFile,
+		LastRefresh: r.last,
+		NextRefresh: r.next,
+		Paused:      r.next.IsZero(),
+	}
+}
+
+// autoRefresh of the dashboard and its widgets, which can be paused.
+type autoRefresh struct {
+	mu     sync.Mutex
+	paused bool
+	// suspended while the config is edited, whether the refresh is paused or not.
+	suspended bool
+	// scheduler refreshing the widgets of the dashboard displayed.
+	scheduler *internal.Scheduler
+}
+
+// use the scheduler of the dashboard displayed.
+func (a *autoRefresh) use(s *internal.Scheduler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.scheduler = s
+	s.Pause(a.paused || a.suspended)
+}
+
+// suspend the refresh of the widgets, for example while the config is edited.
+// Return a function to restore the refresh as it was.
+func (a *autoRefresh) suspend() (restore func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.suspended = true
+	if a.scheduler != nil {
+		a.scheduler.Pause(true)
+	}
+
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.suspended = false
+		if a.scheduler != nil {
+			a.scheduler.Pause(a.paused)
+		}
+	}
+}
+
+func (a *autoRefresh) isSuspended() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.suspended
+}
+
+// toggle the automatic refresh: call pause or resume depending on its state.
+// They're called without holding the lock.
+func (a *autoRefresh) toggle(pause func(), resume func()) {
+	a.mu.Lock()
+	a.paused = !a.paused
+	paused := a.paused
+	if a.scheduler != nil {
+		a.scheduler.Pause(paused || a.suspended)
+	}
+	a.mu.Unlock()
+
+	if paused {
+		pause()
+	} else {
+		resume()
+	}
+}
+
+func (a *autoRefresh) isPaused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.paused
+}
+
+// periodic refresh of the widgets which don't have their own refresh interval.
+func (a *autoRefresh) periodic() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.scheduler != nil {
+		a.scheduler.RefreshDefault()
+	}
+}
+
+// widget drawn in a cell to refresh now.
+func (a *autoRefresh) widget(cell int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.scheduler != nil && cell != 0 {
+		a.scheduler.Refresh(cell)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal"
+)
+
+func Test_pagerCurrent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		moves    []int
+		pages    int
+		expected int
+	}{
+		{
+			name:     "first page",
+			pages:    3,
+			expected: 0,
+		},
+		{
+			name:     "next page",
+			moves:    []int{1},
+			pages:    3,
+			expected: 1,
+		},
+		{
+			name:     "after the last page",
+			moves:    []int{1, 1, 1},
+			pages:    3,
+			expected: 0,
+		},
+		{
+			name:     "before the first page",
+			moves:    []int{-1},
+			pages:    3,
+			expected: 2,
+		},
+		{
+			name:     "no page",
+			moves:    []int{1},
+			pages:    0,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &pager{}
+			for _, m := range tc.moves {
+				p.move(m)
+			}
+
+			actual := p.current(tc.pages)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_autoRefreshToggle(t *testing.T) {
+	a := &autoRefresh{}
+	calls := []string{}
+	pause := func() { calls = append(calls, "pause") }
+	resume := func() { calls = append(calls, "resume") }
+
+	a.toggle(pause, resume)
+	if !a.isPaused() {
+		t.Errorf("Expected the refresh to be paused")
+	}
+
+	a.toggle(pause, resume)
+	if a.isPaused() {
+		t.Errorf("Expected the refresh to be resumed")
+	}
+
+	expected := "pause,resume"
+	if actual := strings.Join(calls, ","); actual != expected {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_autoRefreshToggleUnlocked(t *testing.T) {
+	a := &autoRefresh{}
+	// Pausing waits for the reload of the dashboard, which uses the scheduler.
+	a.toggle(func() { a.use(internal.NewScheduler(context.Background(), nil, 0, nil)) }, func() {})
+
+	if !a.isPaused() {
+		t.Errorf("Expected the refresh to be paused")
+	}
+}
+
+// fakeTicks replace the ticks of a refreshTicker, and record their intervals.
+type fakeTicks struct {
+	ticks     chan time.Time
+	intervals chan time.Duration
+	stopped   chan bool
+}
+
+func newFakeTicks(r *refreshTicker) *fakeTicks {
+	f := &fakeTicks{
+		ticks:     make(chan time.Time),
+		intervals: make(chan time.Duration, 10),
+		stopped:   make(chan bool, 10),
+	}
+	r.ticks = func(d time.Duration) (<-chan time.Time, func()) {
+		f.intervals <- d
+		return f.ticks, func() { f.stopped <- true }
+	}
+
+	return f
+}
+
+func Test_refreshTickerHaltDuringRefresh(t *testing.T) {
+	// Nobody receives the tick, as if the widgets were still refreshing.
+	r := newRefreshTicker(60, make(chan time.Time), &reloadStatus{})
+	ticks := newFakeTicks(r)
+	r.start()
+	<-ticks.intervals
+	ticks.ticks <- time.Now()
+
+	r.halt()
+	select {
+	case <-ticks.stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the ticker to stop")
+	}
+
+	if bar := r.status.bar(""); !bar.Paused {
+		t.Errorf("Expected the automatic refresh to be paused")
+	}
+}
+
+func Test_refreshTickerReset(t *testing.T) {
+	testCases := []struct {
+		name   string
+		halted bool
+	}{
+		{
+			name: "running ticker",
+		},
+		{
+			name:   "halted ticker",
+			halted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newRefreshTicker(60, make(chan time.Time), &reloadStatus{})
+			ticks := newFakeTicks(r)
+			r.start()
+			<-ticks.intervals
+			if tc.halted {
+				r.halt()
+			}
+
+			r.reset(3600)
+			<-ticks.stopped
+			if tc.halted {
+				// The ticker stays halted till it's started again.
+				if !r.status.bar("").Paused {
+					t.Errorf("Expected the automatic refresh to be paused")
+				}
+				r.start()
+			}
+
+			if actual := <-ticks.intervals; actual != time.Hour {
+				t.Errorf("Expected %v, actual %v", time.Hour, actual)
+			}
+			r.halt()
+		})
+	}
+}
+
+func Test_autoRefreshSuspend(t *testing.T) {
+	a := &autoRefresh{}
+	a.toggle(func() {}, func() {})
+
+	restore := a.suspend()
+	a.use(internal.NewScheduler(context.Background(), nil, 0, nil))
+	if !a.isSuspended() || !a.paused {
+		t.Errorf("Expected the refresh to be suspended and paused")
+	}
+
+	restore()
+	if a.isSuspended() || !a.paused {
+		t.Errorf("Expected the refresh to be paused as before")
+	}
+}
@@ -0,0 +1,541 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func validateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [config]",
+		Short: "Check a dashboard configuration and report its problems",
+		Long:  "Check the structure of a dashboard configuration, its services, its widgets and their options. Each problem is reported with its line in the config file. The secret commands aren't run. Exit with the status 1 if there is any problem.",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := cfgName
+			if len(args) > 0 {
+				file = args[0]
+			}
+
+			used, problems := validateConfig(file, cfgVars)
+			for _, p := range problems {
+				fmt.Println(p.format(used))
+			}
+
+			if len(problems) > 0 {
+				os.Exit(1)
+			}
+			fmt.Printf("%s is valid\n", used)
+		},
+	}
+}
+
+// configProblem in a config file, at the path of the field with the problem (for example "projects.0.name").
+// The file is set if the problem is in a file included by the config.
+type configProblem struct {
+	path    string
+	file    string
+	line    int
+	message string
+}
+
+func (p configProblem) format(file string) string {
+	if p.file != "" {
+		file = p.file
+	}
+	if p.line > 0 {
+		return fmt.Sprintf("%s:%d: %s", file, p.line, p.message)
+	}
+	if p.path != "" {
+		return fmt.Sprintf("%s: %s: %s", file, p.path, p.message)
+	}
+
+	return fmt.Sprintf("%s: %s", file, p.message)
+}
+
+// errorLine of the parsing errors, for example "yaml: line 3: did not find expected key".
+var errorLine = regexp.MustCompile(`line (\d+)`)
+
+// validateConfig return the path of the config file and its problems, ordered by line.
+// The variables of its values are interpolated, but its secrets aren't resolved: the secret commands aren't run.
+func validateConfig(cfgFile string, vars []string) (string, []configProblem) {
+	v, used, inc, err := openConfig(cfgFile)
+	var values []settingError
+	if err == nil {
+		if values, err = checkValues(v, vars); err != nil {
+			err = errors.Wrapf(err, "could not resolve config %s", used)
+		}
+	}
+	if err != nil {
+		p := configProblem{message: err.Error()}
+		if m := errorLine.FindStringSubmatch(err.Error()); m != nil {
+			p.line, _ = strconv.Atoi(m[1])
+		}
+		if e, ok := errors.Cause(err).(settingError); ok {
+			p = configProblem{path: e.path, message: e.err.Error()}
+		}
+		return used, lineProblems(used, inc, []configProblem{p})
+	}
+
+	problems := checkConfig(v.AllSettings())
+	for _, e := range values {
+		problems = append(problems, configProblem{path: e.path, message: e.err.Error()})
+	}
+	if len(problems) == 0 {
+		if _, err := unmarshalConfig(v, used, inc); err != nil {
+			problems = append(problems, configProblem{message: err.Error()})
+		}
+	}
+
+	return used, lineProblems(used, inc, problems)
+}
+
+// lineProblems find the lines of the problems in the YAML files where they are, the config or the files it includes,
+// and order the problems by file and by line. The problems of the config come first.
+func lineProblems(used string, inc includes, problems []configProblem) []configProblem {
+	files := map[string]lineIndex{}
+	for k := range problems {
+		if problems[k].path == "" {
+			continue
+		}
+
+		file, path := inc.locate(used, problems[k].path)
+		if file != used {
+			problems[k].file = file
+		}
+
+		lines, ok := files[file]
+		if !ok {
+			lines = fileLines(file)
+			files[file] = lines
+		}
+		problems[k].line = lines.find(path)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].file != problems[j].file {
+			return problems[i].file < problems[j].file
+		}
+		return problems[i].line < problems[j].line
+	})
+
+	return problems
+}
+
+// fileLines index the lines of the fields of a YAML file. Other files have no lines.
+func fileLines(file string) lineIndex {
+	ext := filepath.Ext(file)
+	if ext != ".yml" && ext != ".yaml" {
+		return lineIndex{}
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return lineIndex{}
+	}
+
+	return yamlLines(content)
+}
+
+// configChecker check the settings of a config, without mapping them.
+type configChecker struct {
+	problems []configProblem
+}
+
+func checkConfig(settings map[string]interface{}) []configProblem {
+	c := &configChecker{}
+	for _, k := range sortedKeys(settings) {
+		switch k {
+		case "general":
+			c.general("general", settings[k])
+		case "projects":
+			c.list("projects", settings[k], c.project)
+		case "include", "variables":
+		case "themes":
+			themes, _ := c.mapping(k, settings[k])
+			for _, t := range sortedKeys(themes) {
+				c.options(join(k, t), themes[t])
+			}
+		case "rows":
+			rows, _ := c.mapping(k, settings[k])
+			for _, r := range sortedKeys(rows) {
+				c.list(join(k, r), rows[r], func(path string, value interface{}) {})
+			}
+		default:
+			c.add(k, "section %s doesn't exist", k)
+		}
+	}
+
+	return c.problems
+}
+
+func (c *configChecker) add(path string, format string, args ...interface{}) {
+	c.problems = append(c.problems, configProblem{path: path, message: fmt.Sprintf(format, args...)})
+}
+
+func (c *configChecker) general(path string, value interface{}) {
+	general, ok := c.mapping(path, value)
+	if !ok {
+		return
+	}
+
+	for _, k := range sortedKeys(general) {
+		p := join(path, k)
+		switch k {
+		case "refresh", "timeout", "workers":
+			c.integer(p, general[k])
+		case "pages", "status_bar", "mouse":
+			c.boolean(p, general[k])
+		case "editor":
+			c.scalar(p, general[k])
+		case "keys":
+			keys, _ := c.mapping(p, general[k])
+			for _, name := range sortedKeys(keys) {
+				if !contains(keyNames, name) {
+					c.add(join(p, name), "key %s doesn't exist", name)
+				}
+			}
+		case "concurrency":
+			limits, _ := c.mapping(p, general[k])
+			for _, name := range sortedKeys(limits) {
+				c.integer(join(p, name), limits[name])
+			}
+		default:
+			c.add(p, "option %s doesn't exist in the section general", k)
+		}
+	}
+}
+
+func (c *configChecker) project(path string, value interface{}) {
+	project, ok := c.mapping(path, value)
+	if !ok {
+		return
+	}
+
+	services := map[string]map[string]string{}
+	if s, ok := c.mapping(join(path, "services"), project["services"]); ok {
+		for _, k := range sortedKeys(s) {
+			options, _ := c.mapping(join(path, "services", k), s[k])
+			services[k] = stringMap(options)
+			for _, p := range internal.CheckService(k, services[k]) {
+				c.add(join(path, "services", k, p.Field), p.Message)
+			}
+		}
+	}
+
+	themes := map[string]bool{}
+	if t, ok := c.mapping(join(path, "themes"), project["themes"]); ok {
+		for _, k := range sortedKeys(t) {
+			themes[k] = true
+			c.options(join(path, "themes", k), t[k])
+		}
+	}
+
+	for _, k := range sortedKeys(project) {
+		p := join(path, k)
+		switch k {
+		case "name":
+			c.scalar(p, project[k])
+		case "name_options":
+			c.options(p, project[k])
+		case "services", "themes":
+		case "widgets":
+			c.list(p, project[k], func(path string, value interface{}) {
+				row, ok := c.mapping(path, value)
+				if !ok {
+					return
+				}
+				c.keys(path, row, "row")
+				c.list(join(path, "row"), row["row"], func(path string, value interface{}) {
+					col, ok := c.mapping(path, value)
+					if !ok {
+						return
+					}
+					c.keys(path, col, "col")
+					// A column can have one group of widgets, or a list of groups.
+					if _, ok := col["col"].([]interface{}); ok {
+						c.list(join(path, "col"), col["col"], func(path string, value interface{}) {
+							c.widgets(path, value, services, themes)
+						})
+						return
+					}
+					c.widgets(join(path, "col"), col["col"], services, themes)
+				})
+			})
+		default:
+			c.add(p, "option %s doesn't exist in a project", k)
+		}
+	}
+}
+
+// widgets of a column, with their size.
+func (c *configChecker) widgets(path string, value interface{}, services map[string]map[string]string, themes map[string]bool) {
+	group, ok := c.mapping(path, value)
+	if !ok {
+		return
+	}
+	c.keys(path, group, "size", "elements")
+
+	if size, ok := group["size"]; ok {
+		// The grid of the dashboard has 12 columns.
+		if s, err := internal.MapSize(fmt.Sprint(size)); err != nil || s < 1 || s > 12 {
+			c.add(join(path, "size"), "size %v should be one of xxs, xs, s, m, l, xl, xxl or a number from 1 to 12", size)
+		}
+	}
+
+	c.list(join(path, "elements"), group["elements"], func(path string, value interface{}) {
+		widget, ok := c.mapping(path, value)
+		if !ok {
+			return
+		}
+		c.keys(path, widget, "name", "options", "theme", "refresh", "timeout", "size")
+
+		name, ok := widget["name"]
+		if !ok {
+			c.add(path, "widget without name")
+			return
+		}
+
+		for _, k := range []string{"refresh", "timeout"} {
+			if v, ok := widget[k]; ok {
+				c.integer(join(path, k), v)
+			}
+		}
+
+		if theme, ok := widget["theme"]; ok && !themes[fmt.Sprint(theme)] {
+			c.add(join(path, "theme"), "theme %v doesn't exist in the project", theme)
+		}
+
+		options, _ := c.mapping(join(path, "options"), widget["options"])
+		w := internal.Widget{Name: fmt.Sprint(name), Options: stringMap(options)}
+		for _, p := range internal.CheckWidget(w, services) {
+			c.add(join(path, p.Field), p.Message)
+		}
+	})
+}
+
+// options of a theme or of the name of a project.
+func (c *configChecker) options(path string, value interface{}) {
+	options, _ := c.mapping(path, value)
+	for _, k := range sortedKeys(options) {
+		if err := internal.CheckOption(k, fmt.Sprint(options[k])); err != nil {
+			c.add(join(path, k), err.Error())
+		}
+	}
+}
+
+// keys of a mapping, which need to be in the keys allowed.
+func (c *configChecker) keys(path string, m map[string]interface{}, allowed ...string) {
+	for _, k := range sortedKeys(m) {
+		if !contains(allowed, k) {
+			c.add(join(path, k), "option %s doesn't exist here (%s)", k, strings.Join(allowed, ", "))
+		}
+	}
+}
+
+// mapping of a value. A missing value is an empty mapping.
+func (c *configChecker) mapping(path string, value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{}, true
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[strings.ToLower(fmt.Sprint(k))] = val
+		}
+		return m, true
+	}
+
+	c.add(path, "%s should be a mapping of keys and values", lastField(path))
+	return nil, false
+}
+
+// list of values, each checked by the function check.
+func (c *configChecker) list(path string, value interface{}, check func(path string, value interface{})) {
+	if value == nil {
+		return
+	}
+
+	l, ok := value.([]interface{})
+	if !ok {
+		c.add(path, "%s should be a list", lastField(path))
+		return
+	}
+
+	for k, v := range l {
+		check(join(path, strconv.Itoa(k)), v)
+	}
+}
+
+func (c *configChecker) integer(path string, value interface{}) {
+	switch v := value.(type) {
+	case int, int64, uint, uint64:
+		return
+	case string:
+		if _, err := strconv.ParseInt(v, 0, 0); err == nil {
+			return
+		}
+	}
+
+	c.add(path, "%s should be a number, not %v", lastField(path), value)
+}
+
+func (c *configChecker) boolean(path string, value interface{}) {
+	switch v := value.(type) {
+	case bool:
+		return
+	case string:
+		if _, err := strconv.ParseBool(v); err == nil {
+			return
+		}
+	}
+
+	c.add(path, "%s should be true or false, not %v", lastField(path), value)
+}
+
+func (c *configChecker) scalar(path string, value interface{}) {
+	switch value.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		c.add(path, "%s should be a single value", lastField(path))
+	}
+}
+
+func stringMap(m map[string]interface{}) map[string]string {
+	s := make(map[string]string, len(m))
+	for k, v := range m {
+		s[k] = fmt.Sprint(v)
+	}
+
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func join(path ...string) string {
+	parts := []string{}
+	for _, p := range path {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func lastField(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// lineIndex of the fields of a YAML file, indexed by path (for example "projects.0.widgets.1.row").
+type lineIndex map[string]int
+
+// find the line of a field. If the field isn't in the file, the line of its closest parent is returned.
+func (l lineIndex) find(path string) int {
+	for path != "" {
+		if line, ok := l[strings.ToLower(path)]; ok {
+			return line
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+
+	return 0
+}
+
+// yamlLines index the lines of the fields of the first document of a YAML file, like the config read.
+// The fields merged from an anchor have the lines of the anchor.
+func yamlLines(content []byte) lineIndex {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return lineIndex{}
+	}
+
+	index := lineIndex{}
+	index.add("", &doc, map[*yaml.Node]bool{})
+
+	return index
+}
+
+// add the lines of the fields of a node, at the path given. The nodes in progress are skipped, for the recursive
+// aliases.
+func (l lineIndex) add(path string, n *yaml.Node, progress map[*yaml.Node]bool) {
+	if progress[n] {
+		return
+	}
+	progress[n] = true
+	defer delete(progress, n)
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			l.add(path, c, progress)
+		}
+	case yaml.AliasNode:
+		l.add(path, n.Alias, progress)
+	case yaml.SequenceNode:
+		for k, c := range n.Content {
+			p := join(path, strconv.Itoa(k))
+			l[p] = c.Line
+			l.add(p, c, progress)
+		}
+	case yaml.MappingNode:
+		// The fields of the mapping win over the fields merged.
+		for k := 0; k+1 < len(n.Content); k += 2 {
+			if n.Content[k].Tag != "!!merge" {
+				continue
+			}
+			// The first mappings merged win over the next ones.
+			merged := n.Content[k+1]
+			if merged.Kind == yaml.SequenceNode {
+				for i := len(merged.Content) - 1; i >= 0; i-- {
+					l.add(path, merged.Content[i], progress)
+				}
+				continue
+			}
+			l.add(path, merged, progress)
+		}
+		for k := 0; k+1 < len(n.Content); k += 2 {
+			key, value := n.Content[k], n.Content[k+1]
+			if key.Tag == "!!merge" {
+				continue
+			}
+			p := join(path, strings.ToLower(key.Value))
+			l[p] = key.Line
+			l.add(p, value, progress)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_yamlLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		path     string
+		expected int
+	}{
+		{
+			name:     "key",
+			content:  "---\ngeneral:\n  refresh: 600\n",
+			path:     "general.refresh",
+			expected: 3,
+		},
+		{
+			name:     "item of a list",
+			content:  "projects:\n  - name: first\n  - name: second\n",
+			path:     "projects.1.name",
+			expected: 3,
+		},
+		{
+			name: "nested lists",
+			content: `projects:
+  - name: first
+    widgets:
+      - row:
+          - col:
+              size: "M"
+`,
+			path:     "projects.0.widgets.0.row.0.col.size",
+			expected: 6,
+		},
+		{
+			name: "after a multiline text",
+			content: `elements:
+  - name: lh.box_uptime
+    options:
+      title: |
+        name: not a key
+  - name: lh.box_load
+`,
+			path:     "elements.1.name",
+			expected: 6,
+		},
+		{
+			name:     "flow style",
+			content:  "general: {refresh: 600}\nprojects: [{name: first},\n  {name: second}]\n",
+			path:     "projects.1.name",
+			expected: 3,
+		},
+		{
+			name: "alias",
+			content: `base: &base
+  title: uptime
+elements:
+  - name: lh.box_uptime
+    options: *base
+`,
+			path:     "elements.0.options.title",
+			expected: 2,
+		},
+		{
+			name: "merged fields",
+			content: `base: &base
+  title: uptime
+  color: red
+elements:
+  - options:
+      <<: *base
+      color: blue
+`,
+			path:     "elements.0.options.color",
+			expected: 7,
+		},
+		{
+			name: "fields merged from an anchor",
+			content: `base: &base
+  title: uptime
+elements:
+  - options:
+      <<: *base
+`,
+			path:     "elements.0.options.title",
+			expected: 2,
+		},
+		{
+			name:     "recursive alias",
+			content:  "list: &list\n  - *list\n",
+			path:     "list.0",
+			expected: 2,
+		},
+		{
+			name:     "first document only",
+			content:  "general:\n  refresh: 600\n---\nprojects:\n  - name: first\n",
+			path:     "projects.0.name",
+			expected: 0,
+		},
+		{
+			name:     "missing field",
+			content:  "projects:\n  - name: first\n",
+			path:     "projects.0.widgets",
+			expected: 2,
+		},
+		{
+			name:     "unknown path",
+			content:  "projects:\n  - name: first\n",
+			path:     "unknown",
+			expected: 0,
+		},
+		{
+			name:     "invalid YAML",
+			content:  "projects: [\n",
+			path:     "projects",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := yamlLines([]byte(tc.content)).find(tc.path)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_checkConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "valid config",
+			settings: map[string]interface{}{
+				"general": map[string]interface{}{"refresh": 600, "keys": map[string]interface{}{"quit": "C-c"}},
+				"projects": []interface{}{
+					map[interface{}]interface{}{
+						"name": "first",
+						"widgets": []interface{}{
+							map[interface{}]interface{}{"row": []interface{}{
+								map[interface{}]interface{}{"col": map[interface{}]interface{}{
+									"size":     "M",
+									"elements": []interface{}{map[interface{}]interface{}{"name": "lh.box_uptime"}},
+								}},
+							}},
+						},
+					},
+				},
+			},
+			expected: []string{},
+		},
+		{
+			name: "wrong general section",
+			settings: map[string]interface{}{
+				"general": map[string]interface{}{"refresh": "soon", "keys": map[string]interface{}{"jump": "j"}},
+				"extra":   true,
+			},
+			expected: []string{"extra", "general.keys.jump", "general.refresh"},
+		},
+		{
+			name: "wrong widgets",
+			settings: map[string]interface{}{
+				"projects": []interface{}{
+					map[interface{}]interface{}{
+						"widgets": []interface{}{
+							map[interface{}]interface{}{"row": []interface{}{
+								map[interface{}]interface{}{"col": map[interface{}]interface{}{
+									"size": "XXXL",
+									"elements": []interface{}{
+										map[interface{}]interface{}{"name": "gitub.box_stars"},
+										map[interface{}]interface{}{"name": "lh.box", "theme": "missing"},
+									},
+								}},
+							}},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"projects.0.widgets.0.row.0.col.size",
+				"projects.0.widgets.0.row.0.col.elements.0.name",
+				"projects.0.widgets.0.row.0.col.elements.1.theme",
+			},
+		},
+		{
+			name: "size out of the grid",
+			settings: map[string]interface{}{
+				"projects": []interface{}{
+					map[interface{}]interface{}{
+						"widgets": []interface{}{
+							map[interface{}]interface{}{"row": []interface{}{
+								map[interface{}]interface{}{"col": map[interface{}]interface{}{
+									"size":     13,
+									"elements": []interface{}{map[interface{}]interface{}{"name": "lh.box_uptime"}},
+								}},
+								map[interface{}]interface{}{"col": map[interface{}]interface{}{
+									"size":     0,
+									"elements": []interface{}{map[interface{}]interface{}{"name": "lh.box_uptime"}},
+								}},
+							}},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"projects.0.widgets.0.row.0.col.size",
+				"projects.0.widgets.0.row.1.col.size",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []string{}
+			for _, p := range checkConfig(tc.settings) {
+				actual = append(actual, p.path)
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_validateConfigIncludes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name: "option of a project included",
+			files: map[string]string{
+				"main.yml": `include: inc.yml
+projects:
+  - name: main
+`,
+				"inc.yml": `projects:
+  - name: included
+    title_options:
+      color: red
+`,
+			},
+			expected: []string{"inc.yml:3"},
+		},
+		{
+			name: "option of a row included",
+			files: map[string]string{
+				"main.yml": `include: rows.yml
+projects:
+  - name: main
+    widgets:
+      - row: health
+`,
+				"rows.yml": `rows:
+  health:
+    - col:
+        size: 6
+        elements:
+          - name: lh.box_uptime
+            colour: red
+`,
+			},
+			expected: []string{"rows.yml:7"},
+		},
+		{
+			name: "option of the config and of a file included",
+			files: map[string]string{
+				"main.yml": `include: inc.yml
+general:
+  refresh: 10
+  reload: 60
+projects:
+  - name: main
+`,
+				"inc.yml": `general:
+  refresh: 600
+  timeout: 5
+  pages: maybe
+`,
+			},
+			expected: []string{"main.yml:4", "inc.yml:4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "devdash")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			for name, content := range tc.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			used, problems := validateConfig(filepath.Join(dir, "main.yml"), nil)
+			actual := []string{}
+			for _, p := range problems {
+				file := used
+				if p.file != "" {
+					file = p.file
+				}
+				actual = append(actual, fmt.Sprintf("%s:%d", filepath.Base(file), p.line))
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_validateExamples(t *testing.T) {
+	examples, err := filepath.Glob("../example/*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) == 0 {
+		t.Fatal("Expected examples in ../example")
+	}
+
+	for _, e := range examples {
+		t.Run(filepath.Base(e), func(t *testing.T) {
+			used, problems := validateConfig(e, nil)
+			for _, p := range problems {
+				t.Error(p.format(used))
+			}
+		})
+	}
+}
+
+func Test_validateConfigSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ran := filepath.Join(dir, "ran")
+	config := filepath.Join(dir, "main.yml")
+	content := `projects:
+  - name: main
+    services:
+      github:
+        token: "cmd:touch ` + ran + `"
+        owner: "file:` + filepath.Join(dir, "missing") + `"
+        repository: devdash
+    widgets:
+      - row:
+          - col:
+              size: 6
+              elements:
+                - name: gitub.box_stars
+`
+	if err := ioutil.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, problems := validateConfig(config, nil)
+	actual := []string{}
+	for _, p := range problems {
+		actual = append(actual, p.path)
+	}
+
+	expected := []string{"projects.0.services.github.owner", "projects.0.widgets.0.row.0.col.elements.0.name"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+
+	if _, err := os.Stat(ran); err == nil {
+		t.Errorf("Expected the secret command not to run")
+	}
+}
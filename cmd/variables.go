@@ -0,0 +1,127 @@
+package cmd
+
+// Variables of a config, to use the same config as a template for multiple dashboards.
+// The section "variables" declares the variables with a description and an optional default value, and the values
+// use them with "${var.NAME}". The values of the variables are given on the command line with "--var NAME=VALUE".
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// variable declared in the section "variables" of a config.
+type variable struct {
+	name        string
+	description string
+	def         string
+	hasDefault  bool
+}
+
+// configVariables declared by a config, with the values given on the command line (for example "repo=api").
+// Every variable given needs to be declared, and every variable declared without default needs a value.
+func configVariables(declared interface{}, values []string) (map[string]string, error) {
+	variables, err := declaredVariables(declared)
+	if err != nil {
+		return nil, settingError{path: "variables", err: err}
+	}
+
+	given, err := parseVariables(values)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(variables))
+	for _, v := range variables {
+		if value, ok := given[v.name]; ok {
+			vars[v.name] = value
+			continue
+		}
+		if !v.hasDefault {
+			return nil, errors.Errorf("variable %s needs a value: add --var %s=<value>%s", v.name, v.name, describe(v))
+		}
+		vars[v.name] = v.def
+	}
+
+	for _, name := range sortedStringKeys(given) {
+		if _, ok := vars[name]; !ok {
+			return nil, errors.Errorf("variable %s is not declared in the section variables", name)
+		}
+	}
+
+	return vars, nil
+}
+
+// declaredVariables in the section "variables", ordered by name. A variable can have a description and a default.
+func declaredVariables(declared interface{}) ([]variable, error) {
+	if declared == nil {
+		return nil, nil
+	}
+
+	section, ok := settingsMap(declared)
+	if !ok {
+		return nil, errors.New("variables should be a mapping of variables")
+	}
+
+	variables := make([]variable, 0, len(section))
+	for _, name := range sortedKeys(section) {
+		if !isVarName(name) {
+			return nil, errors.Errorf("variable %q should only have letters, digits and underscores", name)
+		}
+
+		v := variable{name: name}
+		if section[name] != nil {
+			options, ok := settingsMap(section[name])
+			if !ok {
+				return nil, errors.Errorf("variable %s should be a mapping with a description and a default", name)
+			}
+			for _, k := range sortedKeys(options) {
+				switch k {
+				case "description":
+					v.description = fmt.Sprint(options[k])
+				case "default":
+					v.def, v.hasDefault = fmt.Sprint(options[k]), true
+				default:
+					return nil, errors.Errorf("option %s doesn't exist for variable %s (description, default)", k, name)
+				}
+			}
+		}
+		variables = append(variables, v)
+	}
+
+	return variables, nil
+}
+
+// parseVariables given on the command line, for example "repo=api".
+func parseVariables(values []string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, v := range values {
+		i := strings.Index(v, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("variable %q should be NAME=VALUE", v)
+		}
+		vars[strings.ToLower(v[:i])] = v[i+1:]
+	}
+
+	return vars, nil
+}
+
+func describe(v variable) string {
+	if v.description == "" {
+		return ""
+	}
+
+	return " (" + v.description + ")"
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
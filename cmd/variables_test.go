@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_configVariables(t *testing.T) {
+	declared := map[string]interface{}{
+		"repo": map[string]interface{}{"description": "Name of the repository"},
+		"host": map[interface{}]interface{}{"default": "web1"},
+		"view": nil,
+	}
+
+	testCases := []struct {
+		name     string
+		declared interface{}
+		values   []string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "values and defaults",
+			declared: declared,
+			values:   []string{"repo=api", "VIEW=123=456"},
+			expected: map[string]string{"repo": "api", "host": "web1", "view": "123=456"},
+		},
+		{
+			name:     "default overridden",
+			declared: declared,
+			values:   []string{"repo=api", "view=", "host=web2"},
+			expected: map[string]string{"repo": "api", "host": "web2", "view": ""},
+		},
+		{
+			name:     "no variable",
+			expected: map[string]string{},
+		},
+		{
+			name:     "value missing",
+			declared: declared,
+			values:   []string{"view=123"},
+			wantErr:  true,
+		},
+		{
+			name:     "variable not declared",
+			declared: declared,
+			values:   []string{"repo=api", "view=123", "branch=main"},
+			wantErr:  true,
+		},
+		{
+			name:     "value without name",
+			declared: declared,
+			values:   []string{"=api"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown option",
+			declared: map[string]interface{}{"repo": map[string]interface{}{"required": true}},
+			wantErr:  true,
+		},
+		{
+			name:     "wrong section",
+			declared: []interface{}{"repo"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := configVariables(tc.declared, tc.values)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// watchDelay between the last change of a config file and the reload of the dashboard.
+// Editors often write a file in several steps.
+const watchDelay = 200 * time.Millisecond
+
+// configWatcher call a function each time one of the config files watched is written.
+// The directories of the files are watched, to keep watching the files the editors replace instead of writing them.
+type configWatcher struct {
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	// files watched, with their absolute paths.
+	files map[string]bool
+	// muted while the config is edited from the dashboard, and till mutedUntil to drop the events of the last save.
+	muted      bool
+	mutedUntil time.Time
+	// stop the call of the function changed after the last change. Nil if no call is waiting.
+	stop func() bool
+
+	// delay between the last change of a file and the call of the function changed.
+	delay time.Duration
+	now   func() time.Time
+	// afterFunc call the function f after the duration d, unless the function returned stops it before.
+	afterFunc func(d time.Duration, f func()) (stop func() bool)
+}
+
+func newConfigWatcher() (*configWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't watch the config files")
+	}
+
+	return &configWatcher{
+		watcher: w,
+		files:   map[string]bool{},
+		delay:   watchDelay,
+		now:     time.Now,
+		afterFunc: func(d time.Duration, f func()) func() bool {
+			return time.AfterFunc(d, f).Stop
+		},
+	}, nil
+}
+
+// watch the files given, instead of the ones watched before.
+func (c *configWatcher) watch(files []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.files = map[string]bool{}
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return errors.Wrapf(err, "can't watch %s", f)
+		}
+		if err := c.watcher.Add(filepath.Dir(abs)); err != nil {
+			return errors.Wrapf(err, "can't watch %s", f)
+		}
+		c.files[abs] = true
+	}
+
+	return nil
+}
+
+// run the function changed after each change of the files watched, till the watcher is closed.
+func (c *configWatcher) run(changed func()) {
+	for {
+		select {
+		case e, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			if e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 || !c.isWatched(e.Name) {
+				continue
+			}
+			c.changed(changed)
+		case _, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// changed call the function after the delay, if no other change happens before and the watcher isn't muted.
+func (c *configWatcher) changed(changed func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.muted || c.now().Before(c.mutedUntil) {
+		return
+	}
+
+	if c.stop != nil {
+		c.stop()
+	}
+	c.stop = c.afterFunc(c.delay, changed)
+}
+
+// mute the watcher, for example while the config is edited from the dashboard. The changes waiting are dropped.
+func (c *configWatcher) mute() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.muted = true
+	if c.stop != nil {
+		c.stop()
+	}
+}
+
+// unmute the watcher. The events of the changes made while it was muted can still arrive: they're dropped too.
+func (c *configWatcher) unmute() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.muted = false
+	c.mutedUntil = c.now().Add(c.delay)
+}
+
+func (c *configWatcher) isWatched(file string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+
+	return c.files[abs]
+}
+
+func (c *configWatcher) close() error {
+	return c.watcher.Close()
+}
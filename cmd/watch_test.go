@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fakeTimers replace the timers of a configWatcher: the functions waiting are called by the test.
+type fakeTimers struct {
+	mu      sync.Mutex
+	waiting []*fakeTimer
+}
+
+type fakeTimer struct {
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimers) afterFunc(d time.Duration, f func()) func() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timer := &fakeTimer{f: f}
+	t.waiting = append(t.waiting, timer)
+
+	return func() bool {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		stopped := timer.stopped
+		timer.stopped = true
+		return !stopped
+	}
+}
+
+// fire the functions which were not stopped. Return the number of functions called.
+func (t *fakeTimers) fire() int {
+	t.mu.Lock()
+	waiting := t.waiting
+	t.waiting = nil
+	t.mu.Unlock()
+
+	fired := 0
+	for _, timer := range waiting {
+		if !timer.stopped {
+			timer.f()
+			fired++
+		}
+	}
+
+	return fired
+}
+
+func Test_configWatcherRun(t *testing.T) {
+	file, err := filepath.Abs("dashboard.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		events   []fsnotify.Event
+		expected int
+	}{
+		{
+			name:     "file written",
+			events:   []fsnotify.Event{{Name: file, Op: fsnotify.Write}},
+			expected: 1,
+		},
+		{
+			name:     "file replaced by the editor",
+			events:   []fsnotify.Event{{Name: file, Op: fsnotify.Rename}, {Name: file, Op: fsnotify.Create}},
+			expected: 1,
+		},
+		{
+			name:     "several writes",
+			events:   []fsnotify.Event{{Name: file, Op: fsnotify.Write}, {Name: file, Op: fsnotify.Write}},
+			expected: 1,
+		},
+		{
+			name:     "file not watched",
+			events:   []fsnotify.Event{{Name: "other.yml", Op: fsnotify.Write}},
+			expected: 0,
+		},
+		{
+			name:     "file only read",
+			events:   []fsnotify.Event{{Name: file, Op: fsnotify.Chmod}},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timers := &fakeTimers{}
+			events := make(chan fsnotify.Event)
+			w := &configWatcher{
+				watcher:   &fsnotify.Watcher{Events: events, Errors: make(chan error)},
+				files:     map[string]bool{file: true},
+				delay:     watchDelay,
+				now:       time.Now,
+				afterFunc: timers.afterFunc,
+			}
+
+			changed := 0
+			done := make(chan bool)
+			go func() {
+				w.run(func() { changed++ })
+				done <- true
+			}()
+			for _, e := range tc.events {
+				events <- e
+			}
+			// The events are handled in order, before the closed channel stops the watcher.
+			close(events)
+			<-done
+
+			timers.fire()
+			if changed != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, changed)
+			}
+		})
+	}
+}
+
+func Test_configWatcherMute(t *testing.T) {
+	now := time.Date(2021, time.May, 10, 10, 0, 0, 0, time.UTC)
+	timers := &fakeTimers{}
+	w := &configWatcher{
+		delay:     watchDelay,
+		now:       func() time.Time { return now },
+		afterFunc: timers.afterFunc,
+	}
+	changed := 0
+	f := func() { changed++ }
+
+	// The change waiting is dropped when the watcher is muted.
+	w.changed(f)
+	w.mute()
+	w.changed(f)
+	if fired := timers.fire(); fired != 0 {
+		t.Errorf("Expected %v, actual %v", 0, fired)
+	}
+
+	// The events of the changes made while the watcher was muted can arrive right after.
+	w.unmute()
+	w.changed(f)
+	if fired := timers.fire(); fired != 0 {
+		t.Errorf("Expected %v, actual %v", 0, fired)
+	}
+
+	now = now.Add(watchDelay)
+	w.changed(f)
+	timers.fire()
+	if changed != 1 {
+		t.Errorf("Expected %v, actual %v", 1, changed)
+	}
+}
+
+func Test_configWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "dashboard.yml")
+	if err := ioutil.WriteFile(file, []byte("projects: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newConfigWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	w.delay = 0
+
+	if err := w.watch([]string{file}); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan bool, 10)
+	go w.run(func() { changed <- true })
+
+	if err := ioutil.WriteFile(file, []byte("projects: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected %v, actual %v", "change", "no change")
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var widgetsJSON bool
+
+func widgetsCmd() *cobra.Command {
+	widgetsCmd := &cobra.Command{
+		Use:   "widgets [service]",
+		Short: "List the services, their widgets and the options of the widgets",
+		Long:  "List every service with its ID, the widgets it provides and the options each widget accepts, with their types and their default values. Give the ID of a service (for example \"ga\") to list only its widgets.",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			defs, err := serviceDefinitions(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if widgetsJSON {
+				err = printWidgetsJSON(os.Stdout, defs)
+			} else {
+				err = printWidgets(os.Stdout, defs)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	widgetsCmd.Flags().BoolVarP(&widgetsJSON, "json", "j", false, "Display the widgets in JSON")
+
+	return widgetsCmd
+}
+
+// serviceDefinitions with the IDs given, or every service if no ID is given.
+func serviceDefinitions(ids []string) ([]internal.ServiceDefinition, error) {
+	defs := internal.ServiceDefinitions()
+	if len(ids) == 0 {
+		return defs, nil
+	}
+
+	for _, d := range defs {
+		if d.ID == ids[0] {
+			return []internal.ServiceDefinition{d}, nil
+		}
+	}
+
+	return nil, errors.Errorf("service %s doesn't exist", ids[0])
+}
+
+// printWidgets as a table: the options of each widget, one per line.
+func printWidgets(out io.Writer, defs []internal.ServiceDefinition) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, d := range defs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s - %s%s\n", d.ID, d.Name, serviceConfig(d))
+		fmt.Fprintln(w, "WIDGET\tOPTION\tTYPE\tDEFAULT\tDESCRIPTION")
+		for _, s := range d.Widgets {
+			name := d.ID + "." + s.Name
+			if len(s.Options) == 0 {
+				fmt.Fprintf(w, "%s\t\t\t\t\n", name)
+			}
+			for _, o := range s.Options {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, o.Name, o.Type, defaultValue(o), o.Description)
+			}
+		}
+	}
+
+	return w.Flush()
+}
+
+// serviceConfig describe where the service is configured, and its options.
+func serviceConfig(d internal.ServiceDefinition) string {
+	if d.ConfigKey == "" {
+		return ""
+	}
+
+	options := make([]string, 0, len(d.Options))
+	for _, o := range d.Options {
+		if o.Env != "" {
+			options = append(options, o.Name+" ($"+o.Env+")")
+			continue
+		}
+		options = append(options, o.Name)
+	}
+
+	return fmt.Sprintf(" (service %s: %s)", d.ConfigKey, strings.Join(options, ", "))
+}
+
+// defaultValue of an option. The strings are quoted to show their spaces.
+func defaultValue(o internal.OptionSchema) string {
+	if o.Default == "" || o.Type != "string" {
+		return o.Default
+	}
+
+	return strconv.Quote(o.Default)
+}
+
+type serviceJSON struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	ConfigKey string              `json:"config_key,omitempty"`
+	Options   []serviceOptionJSON `json:"options"`
+	Widgets   []widgetJSON        `json:"widgets"`
+}
+
+type serviceOptionJSON struct {
+	Name string `json:"name"`
+	Env  string `json:"env,omitempty"`
+}
+
+type widgetJSON struct {
+	Name    string       `json:"name"`
+	Options []optionJSON `json:"options"`
+}
+
+type optionJSON struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+}
+
+// printWidgetsJSON with the services, their widgets and the options of the widgets.
+func printWidgetsJSON(out io.Writer, defs []internal.ServiceDefinition) error {
+	services := make([]serviceJSON, 0, len(defs))
+	for _, d := range defs {
+		s := serviceJSON{
+			ID:        d.ID,
+			Name:      d.Name,
+			ConfigKey: d.ConfigKey,
+			Options:   []serviceOptionJSON{},
+			Widgets:   []widgetJSON{},
+		}
+
+		for _, o := range d.Options {
+			s.Options = append(s.Options, serviceOptionJSON{Name: o.Name, Env: o.Env})
+		}
+
+		for _, w := range d.Widgets {
+			wj := widgetJSON{Name: d.ID + "." + w.Name, Options: []optionJSON{}}
+			for _, o := range w.Options {
+				wj.Options = append(wj.Options, optionJSON{
+					Name:        o.Name,
+					Type:        string(o.Type),
+					Default:     o.Default,
+					Description: o.Description,
+				})
+			}
+			s.Widgets = append(s.Widgets, wj)
+		}
+
+		services = append(services, s)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(services)
+}
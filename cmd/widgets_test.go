@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Phantas0s/devdash/internal"
+)
+
+func Test_serviceDefinitions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ids      []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "one service",
+			ids:      []string{"rh"},
+			expected: []string{"rh"},
+		},
+		{
+			name:    "unknown service",
+			ids:     []string{"remote_host"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defs, err := serviceDefinitions(tc.ids)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			var actual []string
+			for _, d := range defs {
+				actual = append(actual, d.ID)
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_printWidgetsJSON(t *testing.T) {
+	defs, _ := serviceDefinitions([]string{"rh"})
+
+	var out bytes.Buffer
+	if err := printWidgetsJSON(&out, defs); err != nil {
+		t.Fatal(err)
+	}
+
+	var services []serviceJSON
+	if err := json.Unmarshal(out.Bytes(), &services); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 1 || services[0].ConfigKey != "remote_host" {
+		t.Fatalf("Expected service remote_host, actual %v", services)
+	}
+
+	actual := ""
+	for _, w := range services[0].Widgets {
+		if w.Name != "rh.box_disk_io" {
+			continue
+		}
+		for _, o := range w.Options {
+			if o.Name == "unit" {
+				actual = o.Default
+			}
+		}
+	}
+
+	if actual != "kb" {
+		t.Errorf("Expected %v, actual %v", "kb", actual)
+	}
+}
+
+func Test_printWidgets(t *testing.T) {
+	var out bytes.Buffer
+	if err := printWidgets(&out, internal.ServiceDefinitions()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, w := range []string{"rh.bar_rates", "ga.bar_new_returning", "display.box"} {
+		if !strings.Contains(out.String(), "\n"+w+" ") {
+			t.Errorf("Expected widget %s in the list", w)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gokit
 
 import (
 	"bytes"
+	"context"
 	"os/exec"
 	"strings"
 )
@@ -9,6 +10,11 @@ import (
 // execCmd from a string. Support pipes. Single quote deleted.
 // Example: "/bin/df -x devtmpfs -x tmpfs -x debugfs | sed -n '1!p'"
 func ExecCmd(command string) (out, errs []byte, pipeLineError error) {
+	return ExecCmdContext(context.Background(), command)
+}
+
+// ExecCmdContext is like ExecCmd, but every command of the pipeline is killed if the context is done.
+func ExecCmdContext(ctx context.Context, command string) (out, errs []byte, pipeLineError error) {
 	cmds := []*exec.Cmd{}
 	piped := strings.Split(command, "|")
 	for _, v := range piped {
@@ -16,7 +22,7 @@ func ExecCmd(command string) (out, errs []byte, pipeLineError error) {
 		for k, v := range c[1:] {
 			c[k+1] = strings.Replace(v, "'", "", -1)
 		}
-		cmds = append(cmds, exec.Command(strings.TrimSpace(c[0]), c[1:]...))
+		cmds = append(cmds, exec.CommandContext(ctx, strings.TrimSpace(c[0]), c[1:]...))
 	}
 
 	var stderr bytes.Buffer
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+)
+
+const (
+	actionOpen    = "open"
+	actionCopy    = "copy"
+	actionCommand = "command"
+	actionNone    = "none"
+)
+
+// rowActions of a table, for each row after the header.
+// The option "action" choose what the actions do: open the URL of the row (the default if the rows have URLs),
+// copy the URL (or the row itself if it has no URL), or run the command of the option "action_command".
+// The command get the URL and the row (its cells separated by tabs) in the variables $DEVDASH_URL and $DEVDASH_ROW.
+func rowActions(data [][]string, urls []string, options map[string]string) ([]func() error, error) {
+	action := options[optionAction]
+	if action == "" && hasURL(urls) {
+		action = actionOpen
+	}
+	if action == "" || action == actionNone || len(data) < 2 {
+		return nil, nil
+	}
+
+	command := options[optionActionCommand]
+	if action == actionCommand && command == "" {
+		return nil, errors.Errorf("option %s is required for the action %s", optionActionCommand, actionCommand)
+	}
+
+	actions := make([]func() error, len(data)-1)
+	for k, v := range data[1:] {
+		url, row := "", strings.Join(v, "\t")
+		if k < len(urls) {
+			url = urls[k]
+		}
+
+		switch action {
+		case actionOpen:
+			actions[k] = func() error { return platform.OpenURL(url) }
+		case actionCopy:
+			text := url
+			if text == "" {
+				text = row
+			}
+			actions[k] = func() error { return platform.CopyToClipboard(text) }
+		case actionCommand:
+			env := []string{"DEVDASH_URL=" + url, "DEVDASH_ROW=" + row}
+			actions[k] = func() error { return platform.RunCommand(command, env) }
+		default:
+			return nil, errors.Errorf("action %s doesn't exist (%s, %s, %s or %s)", action, actionOpen, actionCopy, actionCommand, actionNone)
+		}
+	}
+
+	return actions, nil
+}
+
+func hasURL(urls []string) bool {
+	for _, v := range urls {
+		if v != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// linkedTable is a table with the URLs of its rows, cached together.
+type linkedTable struct {
+	Rows [][]string `json:"rows"`
+	URLs []string   `json:"urls"`
+}
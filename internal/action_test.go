@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"testing"
+)
+
+func Test_rowActions(t *testing.T) {
+	data := [][]string{{"name", "state"}, {"first", "open"}, {"second", "closed"}}
+
+	testCases := []struct {
+		name     string
+		urls     []string
+		options  map[string]string
+		expected int
+		wantErr  bool
+	}{
+		{
+			name:     "no action without URLs",
+			options:  map[string]string{},
+			expected: 0,
+		},
+		{
+			name:     "open the URLs by default",
+			urls:     []string{"https://example.com/1", "https://example.com/2"},
+			options:  map[string]string{},
+			expected: 2,
+		},
+		{
+			name:     "no action if disabled",
+			urls:     []string{"https://example.com/1", "https://example.com/2"},
+			options:  map[string]string{optionAction: actionNone},
+			expected: 0,
+		},
+		{
+			name:     "copy the rows without URLs",
+			options:  map[string]string{optionAction: actionCopy},
+			expected: 2,
+		},
+		{
+			name:    "command without action_command",
+			options: map[string]string{optionAction: actionCommand},
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			options: map[string]string{optionAction: "print"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := rowActions(data, tc.urls, tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if len(actual) != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, len(actual))
+			}
+		})
+	}
+}
+
+func Test_rowActionsCommand(t *testing.T) {
+	data := [][]string{{"name", "state"}, {"first", "open"}}
+	options := map[string]string{
+		optionAction:        actionCommand,
+		optionActionCommand: `test "$DEVDASH_URL" = "https://example.com/1" && test "$DEVDASH_ROW" = "$(printf 'first\topen')"`,
+	}
+
+	actions, err := rowActions(data, []string{"https://example.com/1"}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := actions[0](); err != nil {
+		t.Errorf("Expected %v, actual %v", nil, err)
+	}
+}
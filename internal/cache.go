@@ -0,0 +1,245 @@
+package internal
+
+// Cache the responses of the services on disk, to spare the quota of the APIs and to display the dashboard right away.
+// A response is cached for a given time (TTL), configurable per service and per widget with the option "cache_ttl" (in seconds).
+// When a response is expired, it's still displayed while the fresh data is fetched in the background.
+// The responses which were not fetched again for a while (CacheRetention) are deleted with Prune.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const optionCacheTTL = "cache_ttl"
+
+// CacheRetention of the responses which are not fetched again, for example the responses of the widgets removed.
+const CacheRetention = 30 * 24 * time.Hour
+
+// Cache of the responses of the services, stored in a directory.
+type Cache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewCache storing the responses in the directory given.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// get the response for a key in v. Return false if there is no response cached.
+func (c *Cache) get(key string, v interface{}) (time.Time, bool) {
+	b, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return time.Time{}, false
+	}
+
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return time.Time{}, false
+	}
+
+	return e.FetchedAt, true
+}
+
+// set the response v for a key.
+func (c *Cache) set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "can't encode response to cache")
+	}
+
+	b, err := json.Marshal(cacheEntry{FetchedAt: c.now(), Data: data})
+	if err != nil {
+		return errors.Wrap(err, "can't encode response to cache")
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return errors.Wrapf(err, "can't create cache directory %s", c.dir)
+	}
+
+	// Write in a temporary file first, to never read a response partially written.
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return errors.Wrapf(err, "can't write in cache directory %s", c.dir)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "can't write in cache directory %s", c.dir)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "can't write in cache directory %s", c.dir)
+	}
+
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Prune the responses cached before the retention given, and the temporary files left.
+func (c *Cache) Prune(retention time.Duration) error {
+	files, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "can't read cache directory %s", c.dir)
+	}
+
+	for _, f := range files {
+		name := f.Name()
+		before := c.now().Add(-retention)
+		switch {
+		case f.IsDir():
+			continue
+		case strings.HasPrefix(name, "tmp-"):
+			// A temporary file is only left if DevDash stopped while writing it.
+			before = c.now().Add(-time.Hour)
+		case filepath.Ext(name) != ".json":
+			continue
+		}
+
+		if !f.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "can't delete cache file %s", name)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(h[:])+".json")
+}
+
+// cacheState of a fetch, passed via its context.
+type cacheState struct {
+	cache *Cache
+	// refresh the responses even if they're cached.
+	refresh bool
+
+	mu    sync.Mutex
+	stale bool
+}
+
+type cacheStateKey struct{}
+
+// withCache return a context using the cache for the responses of the services.
+// If refresh is true, the services are queried even if their responses are cached.
+func withCache(ctx context.Context, c *Cache, refresh bool) (context.Context, *cacheState) {
+	s := &cacheState{cache: c, refresh: refresh}
+	return context.WithValue(ctx, cacheStateKey{}, s), s
+}
+
+// isStale return true if an expired response was used.
+func (s *cacheState) isStale() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stale
+}
+
+func (s *cacheState) markStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stale = true
+}
+
+// serviceCache cache the responses of an instance of a service.
+type serviceCache struct {
+	// id of the service, depending on its configuration.
+	id  string
+	ttl time.Duration
+}
+
+// newServiceCache for a service with the TTL given by the option "cache_ttl" of its configuration.
+// The responses are not cached if there is no TTL.
+func newServiceCache(serviceID string, config map[string]string) (serviceCache, error) {
+	ttl, err := parseCacheTTL(config[optionCacheTTL])
+	if err != nil {
+		return serviceCache{}, err
+	}
+
+	return serviceCache{id: configID(serviceID, config, optionCacheTTL), ttl: ttl}, nil
+}
+
+func parseCacheTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+
+	s, err := strconv.ParseInt(ttl, 10, 0)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s should be a number of seconds", optionCacheTTL)
+	}
+
+	return time.Duration(s) * time.Second, nil
+}
+
+// fetch the response of a query in v, from the cache if it's there and from the function query otherwise.
+// The query is identified by its name and its parameters. The option "cache_ttl" of the widget overrides the TTL of the service.
+// An expired response is used if the fetch doesn't explicitly refresh the responses; the fetch is then marked as stale.
+func (s serviceCache) fetch(ctx context.Context, widget Widget, query string, params interface{}, v interface{}, f func() error) error {
+	ttl := s.ttl
+	if t, ok := widget.Options[optionCacheTTL]; ok {
+		var err error
+		if ttl, err = parseCacheTTL(t); err != nil {
+			return err
+		}
+	}
+
+	state, _ := ctx.Value(cacheStateKey{}).(*cacheState)
+	if ttl <= 0 || state == nil || state.cache == nil {
+		return f()
+	}
+
+	p, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrapf(err, "can't create cache key for query %s", query)
+	}
+	key := s.id + "|" + query + "|" + string(p)
+
+	if !state.refresh {
+		if fetchedAt, ok := state.cache.get(key, v); ok {
+			if state.cache.now().Sub(fetchedAt) > ttl {
+				state.markStale()
+			}
+			return nil
+		}
+	}
+
+	if err := f(); err != nil {
+		return err
+	}
+
+	// A cache which can't be written shouldn't prevent the widget to be displayed.
+	_ = state.cache.set(key, v)
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_serviceCacheFetch(t *testing.T) {
+	testCases := []struct {
+		name          string
+		expected      string
+		expectedQuery bool
+		expectedStale bool
+		cached        string
+		cachedAgo     time.Duration
+		ttl           string
+		widget        Widget
+		refresh       bool
+	}{
+		{
+			name:          "no ttl",
+			expected:      "fresh",
+			expectedQuery: true,
+			cached:        "cached",
+		},
+		{
+			name:     "response cached",
+			expected: "cached",
+			cached:   "cached",
+			ttl:      "60",
+		},
+		{
+			name:          "nothing cached",
+			expected:      "fresh",
+			expectedQuery: true,
+			ttl:           "60",
+		},
+		{
+			name:          "response expired",
+			expected:      "cached",
+			expectedStale: true,
+			cached:        "cached",
+			cachedAgo:     2 * time.Minute,
+			ttl:           "60",
+		},
+		{
+			name:          "refresh the response",
+			expected:      "fresh",
+			expectedQuery: true,
+			cached:        "cached",
+			ttl:           "60",
+			refresh:       true,
+		},
+		{
+			name:          "ttl of the widget",
+			expected:      "fresh",
+			expectedQuery: true,
+			cached:        "cached",
+			ttl:           "60",
+			widget:        Widget{Options: map[string]string{optionCacheTTL: "0"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "devdash")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			sc, err := newServiceCache("test", map[string]string{optionCacheTTL: tc.ttl})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewCache(dir)
+			key := sc.id + "|query|\"param\""
+			if tc.cached != "" {
+				c.now = func() time.Time { return time.Now().Add(-tc.cachedAgo) }
+				if err := c.set(key, tc.cached); err != nil {
+					t.Fatal(err)
+				}
+				c.now = time.Now
+			}
+
+			ctx, state := withCache(context.Background(), c, tc.refresh)
+			queried := false
+			var actual string
+			err = sc.fetch(ctx, tc.widget, "query", "param", &actual, func() error {
+				queried = true
+				actual = "fresh"
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Expected no error, actual %v", err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+
+			if queried != tc.expectedQuery {
+				t.Errorf("Expected query %v, actual %v", tc.expectedQuery, queried)
+			}
+
+			if state.isStale() != tc.expectedStale {
+				t.Errorf("Expected stale %v, actual %v", tc.expectedStale, state.isStale())
+			}
+		})
+	}
+}
+
+func Test_newServiceCache(t *testing.T) {
+	a, _ := newServiceCache("github", map[string]string{"owner": "Phantas0s", optionCacheTTL: "60"})
+	b, _ := newServiceCache("github", map[string]string{"owner": "Phantas0s", optionCacheTTL: "120"})
+	c, _ := newServiceCache("github", map[string]string{"owner": "someone"})
+
+	if a.id != b.id {
+		t.Errorf("Expected %v, actual %v", a.id, b.id)
+	}
+
+	if a.id == c.id {
+		t.Errorf("Expected a different ID than %v", a.id)
+	}
+
+	if _, err := newServiceCache("github", map[string]string{optionCacheTTL: "one hour"}); err == nil {
+		t.Errorf("Expected error for invalid %s", optionCacheTTL)
+	}
+}
+
+func Test_Prune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	files := map[string]time.Duration{
+		"recent.json":  time.Hour,
+		"old.json":     40 * 24 * time.Hour,
+		"tmp-recent":   time.Minute,
+		"tmp-old":      2 * time.Hour,
+		"old-notes.md": 40 * 24 * time.Hour,
+	}
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewCache(dir).Prune(CacheRetention); err != nil {
+		t.Fatalf("Expected no error, actual %v", err)
+	}
+
+	expected := []string{"old-notes.md", "recent.json", "tmp-recent"}
+	actual := []string{}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range infos {
+		actual = append(actual, f.Name())
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+
+	if err := NewCache(filepath.Join(dir, "missing")).Prune(CacheRetention); err != nil {
+		t.Errorf("Expected no error without cache directory, actual %v", err)
+	}
+}
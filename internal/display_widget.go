@@ -1,6 +1,10 @@
 package internal
 
-import "github.com/pkg/errors"
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
 
 type displayWidget struct {
 	tui *Tui
@@ -8,11 +12,29 @@ type displayWidget struct {
 
 const displayBox = "display.box"
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:   "display",
+		Name: "Display",
+		Widgets: []WidgetSchema{
+			widgetSchema(
+				displayBox,
+				titleOption(""),
+				[]OptionSchema{{Name: optionContent, Type: typeString, Default: "No content", Description: "Text displayed in the box."}},
+				textBoxOptions,
+			),
+		},
+		New: func(map[string]string) (service, error) {
+			return NewDisplayWidget(), nil
+		},
+	})
+}
+
 func NewDisplayWidget() *displayWidget {
 	return &displayWidget{}
 }
 
-func (d displayWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (d displayWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	d.tui = tui
 
 	switch widget.Name {
@@ -26,15 +48,9 @@ func (d displayWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, e
 }
 
 func (d displayWidget) box(widget Widget) (f func() error, err error) {
-	title := ""
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	content := "No content"
-	if _, ok := widget.Options[optionContent]; ok {
-		content = widget.Options[optionContent]
-	}
+	content := widget.option(optionContent)
 
 	f = func() error {
 		return d.tui.AddTextBox(
@@ -57,3 +73,22 @@ func DisplayError(tui *Tui, err error) func() error {
 		})
 	}
 }
+
+// DisplayLoading display a placeholder while the data of a widget are fetched.
+func DisplayLoading(tui *Tui, widget Widget) func() error {
+	title := " " + widget.Name + " "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	options := map[string]string{}
+	for _, o := range []string{optionHeight, optionColor, optionBorderColor, optionTitleColor} {
+		if v, ok := widget.Options[o]; ok {
+			options[o] = v
+		}
+	}
+
+	return func() error {
+		return tui.AddTextBox("Loading...", title, options)
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+// Diagnose the services of a dashboard: their credentials and their connectivity.
+// A service registers its checks with the function Doctor of its definition. The services without checks are only created.
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Diagnosis is the result of a check of a service, with a hint to fix the problem if the check failed.
+type Diagnosis struct {
+	Check string
+	Err   error
+	Hint  string
+}
+
+// ServiceDiagnosis of a service configured in the "services" section of a project.
+type ServiceDiagnosis struct {
+	// Service name, with the name of its instance if any.
+	Service string
+	// Key of the service in the "services" section (for example "remote_host:web1").
+	Key       string
+	Diagnoses []Diagnosis
+}
+
+// Failed is true if one of the checks of the service failed.
+func (s ServiceDiagnosis) Failed() bool {
+	for _, d := range s.Diagnoses {
+		if d.Err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diagnoses of a service, added one check after the other.
+type diagnoses []Diagnosis
+
+// add the result of a check. Return true if the check passed.
+func (d *diagnoses) add(check string, err error, hint string) bool {
+	*d = append(*d, Diagnosis{Check: check, Err: err, Hint: hint})
+	return err == nil
+}
+
+// requireOption of the configuration of a service.
+func requireOption(config map[string]string, name string) error {
+	if strings.TrimSpace(config[name]) == "" {
+		return errors.Errorf("option %s is empty", name)
+	}
+
+	return nil
+}
+
+// Diagnose every service configured in the "services" section of the projects, concurrently.
+// A service configured the same way in multiple projects is only checked once.
+func Diagnose(ctx context.Context, projects []map[string]map[string]string) []ServiceDiagnosis {
+	type job struct {
+		def    ServiceDefinition
+		config map[string]string
+	}
+
+	results := []ServiceDiagnosis{}
+	jobs := []job{}
+	seen := map[string]bool{}
+	for _, services := range projects {
+		keys := make([]string, 0, len(services))
+		for k := range services {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			configKey, instance := splitInstance(strings.ToLower(k))
+			def, ok := lookupConfigKey(configKey)
+			if !ok {
+				results = append(results, ServiceDiagnosis{
+					Service:   k,
+					Key:       k,
+					Diagnoses: []Diagnosis{{Check: "service exists", Err: errors.Errorf("service %s doesn't exist", k), Hint: "Run \"devdash widgets\" to list the services."}},
+				})
+				jobs = append(jobs, job{})
+				continue
+			}
+
+			conf := def.config(services[k])
+			id := configID(def.ID, conf, optionCacheTTL)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			results = append(results, ServiceDiagnosis{Service: serviceName(def, instance), Key: k})
+			jobs = append(jobs, job{def: def, config: conf})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		if j.def.ID == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, j job) {
+			defer wg.Done()
+			results[i].Diagnoses = diagnose(ctx, j.def, j.config)
+		}(i, j)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// diagnose a service with its checks, or by creating it if it has no check.
+func diagnose(ctx context.Context, def ServiceDefinition, config map[string]string) []Diagnosis {
+	if def.Doctor != nil {
+		return def.Doctor(ctx, config)
+	}
+
+	d := diagnoses{}
+	s, err := def.New(config)
+	if d.add("create the service", err, "Check the options of the service.") {
+		closeService(s)
+	}
+
+	return d
+}
+
+func lookupConfigKey(configKey string) (ServiceDefinition, bool) {
+	for _, def := range ServiceDefinitions() {
+		if def.ConfigKey != "" && def.ConfigKey == configKey {
+			return def, true
+		}
+	}
+
+	return ServiceDefinition{}, false
+}
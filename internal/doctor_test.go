@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func Test_Diagnose(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	testCases := []struct {
+		name     string
+		projects []map[string]map[string]string
+		expected []string
+		failed   []bool
+	}{
+		{
+			name: "services passing and failing",
+			projects: []map[string]map[string]string{
+				{
+					"monitor":      {"address": up.URL},
+					"monitor:down": {"address": down.URL},
+				},
+			},
+			expected: []string{"Monitor", "Monitor (down)"},
+			failed:   []bool{false, true},
+		},
+		{
+			name: "same service in multiple projects",
+			projects: []map[string]map[string]string{
+				{"monitor": {"address": up.URL}},
+				{"monitor": {"address": up.URL, "cache_ttl": "60"}},
+				{"monitor": {"address": down.URL}},
+			},
+			expected: []string{"Monitor", "Monitor"},
+			failed:   []bool{false, true},
+		},
+		{
+			name: "option not set",
+			projects: []map[string]map[string]string{
+				{"monitor": {}},
+			},
+			expected: []string{"Monitor"},
+			failed:   []bool{true},
+		},
+		{
+			name: "unknown service",
+			projects: []map[string]map[string]string{
+				{"monitoring": {"address": up.URL}},
+			},
+			expected: []string{"monitoring"},
+			failed:   []bool{true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			var failed []bool
+			for _, r := range Diagnose(context.Background(), tc.projects) {
+				actual = append(actual, r.Service)
+				failed = append(failed, r.Failed())
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+
+			if !reflect.DeepEqual(tc.failed, failed) {
+				t.Errorf("Expected %v, actual %v", tc.failed, failed)
+			}
+		})
+	}
+}
@@ -1,12 +1,27 @@
 package internal
 
 import (
+	"context"
 	"github.com/Phantas0s/devdash/internal/platform"
 	"github.com/pkg/errors"
 )
 
 const FeedlySubscribers = "feedly.box_subscribers"
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "feedly",
+		Name:      "Feedly",
+		ConfigKey: "feedly",
+		Options:   []ServiceOption{{Name: "address"}},
+		Widgets:   []WidgetSchema{widgetSchema(FeedlySubscribers, titleOption(" Feedly subscribers "), textBoxOptions)},
+		New: func(config map[string]string) (service, error) {
+			return NewFeedlyWidget(config["address"]), nil
+		},
+		Doctor: feedlyDoctor,
+	})
+}
+
 type feedlyWidget struct {
 	tui    *Tui
 	client *platform.Feedly
@@ -20,12 +35,12 @@ func NewFeedlyWidget(address string) *feedlyWidget {
 	}
 }
 
-func (f feedlyWidget) CreateWidgets(widget Widget, tui *Tui) (fu func() error, err error) {
+func (f feedlyWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (fu func() error, err error) {
 	f.tui = tui
 
 	switch widget.Name {
 	case FeedlySubscribers:
-		fu, err = f.boxSubscribers(widget)
+		fu, err = f.boxSubscribers(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service Feedly", widget.Name)
 	}
@@ -33,13 +48,10 @@ func (f feedlyWidget) CreateWidgets(widget Widget, tui *Tui) (fu func() error, e
 	return
 }
 
-func (f feedlyWidget) boxSubscribers(widget Widget) (fu func() error, err error) {
-	title := " Feedly subscribers "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (f feedlyWidget) boxSubscribers(ctx context.Context, widget Widget) (fu func() error, err error) {
+	title := widget.option(optionTitle)
 
-	subs, err := f.client.Subscribers()
+	subs, err := f.client.Subscribers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -50,3 +62,16 @@ func (f feedlyWidget) boxSubscribers(widget Widget) (fu func() error, err error)
 
 	return
 }
+
+// feedlyDoctor check the address of the service and the Feedly API.
+func feedlyDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add("address is set", requireOption(config, "address"), "Set the option address of the service, with the address of your website or of its feed.") {
+		return d
+	}
+
+	_, err := platform.NewFeedly(config["address"]).Subscribers(ctx)
+	d.add("Feedly API responds", err, "Check your connection to https://feedly.com.")
+
+	return d
+}
@@ -1,8 +1,8 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -31,10 +31,114 @@ const (
 	gaTimeFormat = "2006-01-02"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "ga",
+		Name:      "Google Analytics",
+		ConfigKey: "google_analytics",
+		Options: []ServiceOption{
+			{Name: "keyfile", Env: "DEVDASH_GA_KEYFILE"},
+			{Name: "view_id"},
+			{Name: optionCacheTTL},
+		},
+		Widgets: []WidgetSchema{
+			widgetSchema(gaBoxRealtime, titleOption(" Real time users "), textBoxOptions, cacheOptions),
+			widgetSchema(gaBoxTotal, titleOption(""), gaMetricOptions, gaDateOptions, gaGlobalOptions, textBoxOptions, cacheOptions),
+			widgetSchema(gaBar, gaBarOptions("")),
+			widgetSchema(gaBarSessions, gaBarOptions("")),
+			widgetSchema(gaBarBounces, gaBarOptions(optionMetric)),
+			widgetSchema(gaBarUsers, gaBarOptions(optionMetric)),
+			widgetSchema(gaBarReturning, gaBarOptions(optionMetric, optionDimensions, optionTitle)),
+			widgetSchema(gaBarNewReturning, gaStackedBarOptions),
+			widgetSchema(gaBarPages, gaBarOptions(optionMetric, optionDimensions)),
+			widgetSchema(gaBarCountries, gaBarOptions(optionMetric, optionDimensions)),
+			widgetSchema(gaBarDevices, gaStackedBarOptions),
+			widgetSchema(gaTablePages, gaTableOptions("")),
+			widgetSchema(gaTableTrafficSources, gaTableOptions(optionDimension)),
+			widgetSchema(gaTable, gaTableOptions("")),
+		},
+		New: func(config map[string]string) (service, error) {
+			g, err := NewGaWidget(config["keyfile"], config["view_id"])
+			if err != nil {
+				return nil, err
+			}
+
+			g.cache, err = newServiceCache("ga", config)
+			return g, err
+		},
+		Doctor: gaDoctor,
+	})
+}
+
+var (
+	gaMetricOptions = []OptionSchema{
+		{Name: optionMetric, Type: typeString, Default: "sessions", Description: "Metric displayed (for example sessions, users or page_views)."},
+	}
+
+	gaDateOptions = dateOptions("7_days_ago", "today")
+
+	gaGlobalOptions = []OptionSchema{
+		{Name: optionGlobal, Type: typeBool, Default: "false", Description: "Display the total for the whole period instead of the values per period."},
+	}
+
+	gaStackedBarOptions = joinOptions(
+		titleOption(""),
+		gaMetricOptions,
+		gaDateOptions,
+		[]OptionSchema{
+			{Name: optionTimePeriod, Type: typeString, Default: "day", Description: "Period of each bar (day, month or year)."},
+			{Name: optionFirstColor, Type: typeColor, Default: "blue", Description: "Color of the first dataset."},
+			{Name: optionSecondColor, Type: typeColor, Default: "green", Description: "Color of the second dataset."},
+			{Name: optionThirdColor, Type: typeColor, Default: "yellow", Description: "Color of the third dataset."},
+			{Name: optionFourthColor, Type: typeColor, Default: "red", Description: "Color of the fourth dataset."},
+			{Name: optionFifthColor, Type: typeColor, Default: "magenta", Description: "Color of the fifth dataset."},
+		},
+		stackedBarChartOptions,
+		cacheOptions,
+	)
+)
+
+// gaBarOptions of the bar charts, without the options set by the widget itself.
+func gaBarOptions(set ...string) []OptionSchema {
+	return withoutOptions(joinOptions(
+		titleOption(""),
+		gaMetricOptions,
+		[]OptionSchema{
+			{Name: optionDimensions, Type: typeString, Description: "Dimensions of the metric, separated by commas."},
+			{Name: optionFilters, Type: typeString, Description: "Filters of the data, separated by commas (for example the relative URL of a page for bar_pages)."},
+			{Name: optionTimePeriod, Type: typeString, Default: "day", Description: "Period of each bar (day, month or year)."},
+		},
+		gaDateOptions,
+		gaGlobalOptions,
+		barChartOptions,
+		cacheOptions,
+	), set...)
+}
+
+// gaTableOptions of the tables, without the options set by the widget itself.
+func gaTableOptions(set ...string) []OptionSchema {
+	return withoutOptions(joinOptions(
+		titleOption(""),
+		[]OptionSchema{
+			{Name: optionDimension, Type: typeString, Default: "page_path", Description: "Dimension of the first column."},
+			{Name: optionMetrics, Type: typeString, Default: "sessions,page_views,entrances,unique_page_views", Description: "Metrics displayed, separated by commas."},
+			{Name: optionOrder, Type: typeString, Description: "Order of the rows, separated by commas (for example \"sessions desc\"). The first metric descending by default."},
+			{Name: optionFilters, Type: typeString, Description: "Filters of the data, separated by commas."},
+			{Name: optionRowLimit, Type: typeInt, Default: "5", Description: "Maximum number of rows."},
+			{Name: optionCharLimit, Type: typeInt, Default: "20", Description: "Maximum number of characters of the first column."},
+		},
+		gaDateOptions,
+		gaGlobalOptions,
+		tableOptions,
+		cacheOptions,
+	), set...)
+}
+
 type gaWidget struct {
 	tui       *Tui
 	analytics *platform.Analytics
 	viewID    string
+	cache     serviceCache
 }
 
 // NewGaWidget including all information to connect to the Google Analytics API.
@@ -51,38 +155,38 @@ func NewGaWidget(keyfile string, viewID string) (*gaWidget, error) {
 }
 
 // CreateWidgets for Google Analytics.
-func (g *gaWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (g *gaWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
 
 	switch widget.Name {
 	case gaBoxRealtime:
-		f, err = g.realTimeUser(widget)
+		f, err = g.realTimeUser(ctx, widget)
 	case gaBoxTotal:
-		f, err = g.totalMetric(widget)
+		f, err = g.totalMetric(ctx, widget)
 	case gaBarSessions:
-		f, err = g.barMetric(widget, platform.XHeaderTime)
+		f, err = g.barMetric(ctx, widget, platform.XHeaderTime)
 	case gaBarUsers:
-		f, err = g.users(widget)
+		f, err = g.users(ctx, widget)
 	case gaBar:
-		f, err = g.barMetric(widget, platform.XHeaderTime)
+		f, err = g.barMetric(ctx, widget, platform.XHeaderTime)
 	case gaTablePages:
-		f, err = g.table(widget, "Page")
+		f, err = g.table(ctx, widget, "Page")
 	case gaTableTrafficSources:
-		f, err = g.trafficSource(widget)
+		f, err = g.trafficSource(ctx, widget)
 	case gaBarNewReturning:
-		f, err = g.stackedBarNewReturningUsers(widget)
+		f, err = g.stackedBarNewReturningUsers(ctx, widget)
 	case gaBarDevices:
-		f, err = g.stackedBarDevices(widget)
+		f, err = g.stackedBarDevices(ctx, widget)
 	case gaBarReturning:
-		f, err = g.barReturning(widget)
+		f, err = g.barReturning(ctx, widget)
 	case gaBarPages:
-		f, err = g.barPages(widget)
+		f, err = g.barPages(ctx, widget)
 	case gaBarCountries:
-		f, err = g.barCountries(widget)
+		f, err = g.barCountries(ctx, widget)
 	case gaBarBounces:
-		f, err = g.barBounces(widget)
+		f, err = g.barBounces(ctx, widget)
 	case gaTable:
-		f, err = g.table(widget, widget.Options[optionDimension])
+		f, err = g.table(ctx, widget, widget.Options[optionDimension])
 	default:
 		return nil, errors.Errorf("can't find the widget %s", widget.Name)
 	}
@@ -90,7 +194,7 @@ func (g *gaWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err e
 	return
 }
 
-func (g *gaWidget) totalMetric(widget Widget) (f func() error, err error) {
+func (g *gaWidget) totalMetric(ctx context.Context, widget Widget) (f func() error, err error) {
 	startDate, endDate, err := ExtractTimeRange(time.Now(), widget.Options)
 	if err != nil {
 		return nil, err
@@ -101,23 +205,24 @@ func (g *gaWidget) totalMetric(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	global := false
-	if _, ok := widget.Options[optionGlobal]; ok {
-		global, err = strconv.ParseBool(widget.Options[optionGlobal])
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not parse string %s to bool", widget.Options[optionGlobal])
-		}
+	global, err := widget.boolOption(optionGlobal)
+	if err != nil {
+		return nil, err
 	}
 
-	users, err := g.analytics.SimpleMetric(
-		platform.AnalyticValues{
-			ViewID:    g.viewID,
-			StartDate: startDate.Format(gaTimeFormat),
-			EndDate:   endDate.Format(gaTimeFormat),
-			Global:    global,
-			Metrics:   []string{ExtractMetric(widget.Options)},
-		},
-	)
+	val := platform.AnalyticValues{
+		ViewID:    g.viewID,
+		StartDate: startDate.Format(gaTimeFormat),
+		EndDate:   endDate.Format(gaTimeFormat),
+		Global:    global,
+		Metrics:   []string{ExtractMetric(widget.Options)},
+	}
+
+	var users string
+	err = g.cache.fetch(ctx, widget, "simple_metric", val, &users, func() (err error) {
+		users, err = g.analytics.SimpleMetric(ctx, val)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -130,13 +235,14 @@ func (g *gaWidget) totalMetric(widget Widget) (f func() error, err error) {
 }
 
 // GaRTActiveUser get the real time active users from Google Analytics
-func (g *gaWidget) realTimeUser(widget Widget) (f func() error, err error) {
-	title := " Real time users "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
-
-	users, err := g.analytics.RealTimeUsers(g.viewID)
+func (g *gaWidget) realTimeUser(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
+
+	var users string
+	err = g.cache.fetch(ctx, widget, "real_time_users", g.viewID, &users, func() (err error) {
+		users, err = g.analytics.RealTimeUsers(ctx, g.viewID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +258,7 @@ func (g *gaWidget) realTimeUser(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (g *gaWidget) users(widget Widget) (f func() error, err error) {
+func (g *gaWidget) users(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
@@ -160,10 +266,10 @@ func (g *gaWidget) users(widget Widget) (f func() error, err error) {
 	widget.Options[optionMetric] = "users"
 	xHeader := platform.XHeaderTime
 
-	return g.barMetric(widget, xHeader)
+	return g.barMetric(ctx, widget, xHeader)
 }
 
-func (g *gaWidget) barReturning(widget Widget) (f func() error, err error) {
+func (g *gaWidget) barReturning(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
@@ -172,10 +278,10 @@ func (g *gaWidget) barReturning(widget Widget) (f func() error, err error) {
 	widget.Options[optionDimensions] = "user_type"
 	widget.Options[optionTitle] = " Returning users "
 
-	return g.barMetric(widget, platform.XHeaderTime)
+	return g.barMetric(ctx, widget, platform.XHeaderTime)
 }
 
-func (g *gaWidget) barPages(widget Widget) (f func() error, err error) {
+func (g *gaWidget) barPages(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
@@ -190,10 +296,10 @@ func (g *gaWidget) barPages(widget Widget) (f func() error, err error) {
 		widget.Options[optionTitle] = widget.Options[optionFilters]
 	}
 
-	return g.barMetric(widget, platform.XHeaderTime)
+	return g.barMetric(ctx, widget, platform.XHeaderTime)
 }
 
-func (g *gaWidget) barCountries(widget Widget) (f func() error, err error) {
+func (g *gaWidget) barCountries(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
@@ -204,26 +310,23 @@ func (g *gaWidget) barCountries(widget Widget) (f func() error, err error) {
 		widget.Options[optionTitle] = widget.Options[optionFilters]
 	}
 
-	return g.barMetric(widget, platform.XHeaderOtherDim)
+	return g.barMetric(ctx, widget, platform.XHeaderOtherDim)
 }
 
-func (g *gaWidget) barBounces(widget Widget) (f func() error, err error) {
+func (g *gaWidget) barBounces(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
 	widget.Options[optionMetric] = "bounces"
 	widget.Options[optionTitle] += " Bounces "
 
-	return g.barMetric(widget, platform.XHeaderTime)
+	return g.barMetric(ctx, widget, platform.XHeaderTime)
 }
 
-func (g *gaWidget) barMetric(widget Widget, xHeader uint16) (f func() error, err error) {
-	global := false
-	if _, ok := widget.Options[optionGlobal]; ok {
-		global, err = strconv.ParseBool(widget.Options[optionGlobal])
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not parse string %s to bool", widget.Options[optionGlobal])
-		}
+func (g *gaWidget) barMetric(ctx context.Context, widget Widget, xHeader uint16) (f func() error, err error) {
+	global, err := widget.boolOption(optionGlobal)
+	if err != nil {
+		return nil, err
 	}
 
 	startDate, endDate, err := ExtractTimeRange(time.Now(), widget.Options)
@@ -238,47 +341,48 @@ func (g *gaWidget) barMetric(widget Widget, xHeader uint16) (f func() error, err
 		}
 	}
 
-	timePeriod := "day"
-	if _, ok := widget.Options[optionTimePeriod]; ok {
-		timePeriod = strings.TrimSpace(widget.Options[optionTimePeriod])
-	}
+	timePeriod := strings.TrimSpace(widget.option(optionTimePeriod))
 
 	title := fmt.Sprintf(" %s per %s ", strings.Title(ExtractMetric(widget.Options)), timePeriod)
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	dim, val, err := g.analytics.BarMetric(
-		platform.AnalyticValues{
-			ViewID:     g.viewID,
-			StartDate:  startDate.Format(gaTimeFormat),
-			EndDate:    endDate.Format(gaTimeFormat),
-			TimePeriod: timePeriod,
-			Global:     global,
-			Metrics:    []string{ExtractMetric(widget.Options)},
-			Dimensions: ExtractDimensions(widget.Options),
-			Filters:    filters,
-			XHeaders:   xHeader,
-		},
-	)
+	an := platform.AnalyticValues{
+		ViewID:     g.viewID,
+		StartDate:  startDate.Format(gaTimeFormat),
+		EndDate:    endDate.Format(gaTimeFormat),
+		TimePeriod: timePeriod,
+		Global:     global,
+		Metrics:    []string{ExtractMetric(widget.Options)},
+		Dimensions: ExtractDimensions(widget.Options),
+		Filters:    filters,
+		XHeaders:   xHeader,
+	}
+
+	var res struct {
+		Dim []string
+		Val []int
+	}
+	err = g.cache.fetch(ctx, widget, "bar_metric", an, &res, func() (err error) {
+		res.Dim, res.Val, err = g.analytics.BarMetric(ctx, an)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddBarChart(val, dim, title, widget.Options)
+		return g.tui.AddBarChart(res.Val, res.Dim, title, widget.Options)
 	}
 
 	return f, nil
 }
 
-func (g *gaWidget) table(widget Widget, firstHeader string) (f func() error, err error) {
-	global := false
-	if _, ok := widget.Options[optionGlobal]; ok {
-		global, err = strconv.ParseBool(widget.Options[optionGlobal])
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not parse string %s to bool", widget.Options[optionGlobal])
-		}
+func (g *gaWidget) table(ctx context.Context, widget Widget, firstHeader string) (f func() error, err error) {
+	global, err := widget.boolOption(optionGlobal)
+	if err != nil {
+		return nil, err
 	}
 
 	dimension := "page_path"
@@ -320,42 +424,44 @@ func (g *gaWidget) table(widget Widget, firstHeader string) (f func() error, err
 		}
 	}
 
-	var rowLimit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		rowLimit, err = strconv.ParseInt(widget.Options[optionRowLimit], 0, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
+	rowLimit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	headers, dim, val, err := g.analytics.Table(
-		platform.AnalyticValues{
-			ViewID:     g.viewID,
-			StartDate:  startDate.Format(gaTimeFormat),
-			EndDate:    endDate.Format(gaTimeFormat),
-			Global:     global,
-			Metrics:    metrics,
-			Dimensions: []string{dimension},
-			Filters:    filters,
-			Orders:     orders,
-			RowLimit:   rowLimit,
-		},
-		firstHeader,
-	)
+	an := platform.AnalyticValues{
+		ViewID:     g.viewID,
+		StartDate:  startDate.Format(gaTimeFormat),
+		EndDate:    endDate.Format(gaTimeFormat),
+		Global:     global,
+		Metrics:    metrics,
+		Dimensions: []string{dimension},
+		Filters:    filters,
+		Orders:     orders,
+		RowLimit:   rowLimit,
+	}
+
+	var res struct {
+		Headers []string
+		Dim     []string
+		Val     [][]string
+	}
+	err = g.cache.fetch(ctx, widget, "table", []interface{}{an, firstHeader}, &res, func() (err error) {
+		res.Headers, res.Dim, res.Val, err = g.analytics.Table(ctx, an, firstHeader)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	headers, dim, val := res.Headers, res.Dim, res.Val
 
 	if int(rowLimit) > len(dim) {
 		rowLimit = int64(len(dim))
 	}
 
-	var charLimit int64 = 20
-	if _, ok := widget.Options[optionCharLimit]; ok {
-		charLimit, err = strconv.ParseInt(widget.Options[optionCharLimit], 0, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionCharLimit])
-		}
+	charLimit, err := widget.intOption(optionCharLimit)
+	if err != nil {
+		return nil, err
 	}
 
 	finalTable := formatTable(rowLimit, dim, val, charLimit, headers)
@@ -395,78 +501,74 @@ func formatTable(
 	return table
 }
 
-func (g *gaWidget) trafficSource(widget Widget) (f func() error, err error) {
+func (g *gaWidget) trafficSource(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
 
 	widget.Options[optionDimension] = "traffic_source"
 
-	return g.table(widget, "Source")
+	return g.table(ctx, widget, "Source")
 }
 
-func (g *gaWidget) stackedBarNewReturningUsers(widget Widget) (func() error, error) {
+func (g *gaWidget) stackedBarNewReturningUsers(ctx context.Context, widget Widget) (func() error, error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
 
 	widget.Options[optionDimensions] = "user_type"
 
-	return g.stackedBar(widget)
+	return g.stackedBar(ctx, widget)
 }
 
-func (g *gaWidget) stackedBarDevices(widget Widget) (f func() error, err error) {
+func (g *gaWidget) stackedBarDevices(ctx context.Context, widget Widget) (f func() error, err error) {
 	if widget.Options == nil {
 		widget.Options = map[string]string{}
 	}
 
 	widget.Options[optionDimensions] = "device_category"
 
-	return g.stackedBar(widget)
+	return g.stackedBar(ctx, widget)
 }
 
-func (g *gaWidget) stackedBar(widget Widget) (f func() error, err error) {
+func (g *gaWidget) stackedBar(ctx context.Context, widget Widget) (f func() error, err error) {
 	// defaults
 	startDate, endDate, err := ExtractTimeRange(time.Now(), widget.Options)
 	if err != nil {
 		return nil, err
 	}
 
-	timePeriod := "day"
-	if _, ok := widget.Options[optionTimePeriod]; ok {
-		timePeriod = strings.TrimSpace(widget.Options[optionTimePeriod])
+	timePeriod := strings.TrimSpace(widget.option(optionTimePeriod))
+
+	an := platform.AnalyticValues{
+		ViewID:     g.viewID,
+		StartDate:  startDate.Format(gaTimeFormat),
+		EndDate:    endDate.Format(gaTimeFormat),
+		TimePeriod: timePeriod,
+		Metrics:    []string{ExtractMetric(widget.Options)},
+		Dimensions: ExtractDimensions(widget.Options),
 	}
 
-	dim, val, err := g.analytics.StackedBar(
-		platform.AnalyticValues{
-			ViewID:     g.viewID,
-			StartDate:  startDate.Format(gaTimeFormat),
-			EndDate:    endDate.Format(gaTimeFormat),
-			TimePeriod: timePeriod,
-			Metrics:    []string{ExtractMetric(widget.Options)},
-			Dimensions: ExtractDimensions(widget.Options),
-		},
-	)
+	var res struct {
+		Dim []string
+		Val map[string][]int
+	}
+	err = g.cache.fetch(ctx, widget, "stacked_bar", an, &res, func() (err error) {
+		res.Dim, res.Val, err = g.analytics.StackedBar(ctx, an)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	dim, val := res.Dim, res.Val
 
 	// Only support 5 different colors for now
-	colors := []uint16{blue, green, yellow, red, magenta}
-	if _, ok := widget.Options[optionFirstColor]; ok {
-		colors[0] = colorLookUp[widget.Options[optionFirstColor]]
-	}
-	if _, ok := widget.Options[optionSecondColor]; ok {
-		colors[1] = colorLookUp[widget.Options[optionSecondColor]]
-	}
-	if _, ok := widget.Options[optionThirdColor]; ok {
-		colors[2] = colorLookUp[widget.Options[optionThirdColor]]
-	}
-	if _, ok := widget.Options[optionFourthColor]; ok {
-		colors[3] = colorLookUp[widget.Options[optionFourthColor]]
-	}
-	if _, ok := widget.Options[optionFifthColor]; ok {
-		colors[4] = colorLookUp[widget.Options[optionFifthColor]]
+	colors := []uint16{
+		colorLookUp[widget.option(optionFirstColor)],
+		colorLookUp[widget.option(optionSecondColor)],
+		colorLookUp[widget.option(optionThirdColor)],
+		colorLookUp[widget.option(optionFourthColor)],
+		colorLookUp[widget.option(optionFifthColor)],
 	}
 
 	var data [8][]int
@@ -531,3 +633,33 @@ func ExtractMetric(widgetOptions map[string]string) (metric string) {
 
 	return
 }
+
+// gaDoctor check the keyfile of Google Analytics and the access to the view.
+func gaDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add("keyfile is set", requireOption(config, "keyfile"), "Set the option keyfile of the service, or the environment variable DEVDASH_GA_KEYFILE.") {
+		return d
+	}
+
+	an, err := platform.NewAnalyticsClient(config["keyfile"])
+	if !d.add(
+		"keyfile is the key of a service account",
+		err,
+		"Create a JSON key for a service account in the Google Cloud console. The keyfile is searched in the current directory, then in $XDG_CONFIG_HOME/devdash.",
+	) {
+		return d
+	}
+
+	if !d.add("view_id is set", requireOption(config, "view_id"), "Set the option view_id of the service, with the ID of the view in the settings of Google Analytics.") {
+		return d
+	}
+
+	_, err = an.RealTimeUsers(ctx, config["view_id"])
+	d.add(
+		"access to the view "+config["view_id"],
+		err,
+		"Add the email of the service account (client_email in the keyfile) to the users of the view, and enable the Google Analytics APIs in the Google Cloud console.",
+	)
+
+	return d
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"github.com/Phantas0s/devdash/internal/platform"
 	"github.com/pkg/errors"
 )
@@ -9,6 +10,20 @@ const (
 	gitBranches = "git.table_branches"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "git",
+		Name:      "Git",
+		ConfigKey: "git",
+		Options:   []ServiceOption{{Name: "path"}},
+		Widgets:   []WidgetSchema{widgetSchema(gitBranches, titleOption(" Git Branches "), tableOptions)},
+		New: func(config map[string]string) (service, error) {
+			return NewGitWidget(config["path"]), nil
+		},
+		Doctor: gitDoctor,
+	})
+}
+
 type gitWidget struct {
 	tui    *Tui
 	client *platform.Git
@@ -21,12 +36,12 @@ func NewGitWidget(path string) *gitWidget {
 	}
 }
 
-func (g gitWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (g gitWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
 
 	switch widget.Name {
 	case gitBranches:
-		f, err = g.branches(widget)
+		f, err = g.branches(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service Git", widget.Name)
 	}
@@ -34,13 +49,10 @@ func (g gitWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err e
 	return
 }
 
-func (g gitWidget) branches(widget Widget) (f func() error, err error) {
-	title := " Git Branches "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (g gitWidget) branches(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	data, err := g.client.Branches()
+	data, err := g.client.Branches(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +63,19 @@ func (g gitWidget) branches(widget Widget) (f func() error, err error) {
 
 	return
 }
+
+// gitDoctor check that git is installed and that the path of the service is a repository.
+func gitDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add("git is installed", platform.CheckGit(), "Install git and add it to your $PATH.") {
+		return d
+	}
+
+	d.add(
+		"path is a git repository",
+		platform.NewGit(config["path"]).CheckRepository(ctx),
+		"Set the option path of the service to the directory of a git repository.",
+	)
+
+	return d
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -23,9 +24,76 @@ const (
 	githubBarStars          = "github.bar_stars"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "github",
+		Name:      "Github",
+		ConfigKey: "github",
+		Options: []ServiceOption{
+			{Name: "token", Env: "DEVDASH_GITHUB_TOKEN"},
+			{Name: "owner"},
+			{Name: "repository"},
+			{Name: optionCacheTTL},
+		},
+		Widgets: []WidgetSchema{
+			widgetSchema(githubBoxStars, titleOption(""), githubRepositoryOptions, textBoxOptions, cacheOptions),
+			widgetSchema(githubBoxWatchers, titleOption(" Github Watchers "), githubRepositoryOptions, textBoxOptions, cacheOptions),
+			widgetSchema(githubBoxOpenIssues, titleOption(" Github Open Issues "), githubRepositoryOptions, textBoxOptions, cacheOptions),
+			widgetSchema(
+				githubTableRepositories,
+				titleOption(" Github Repositories "),
+				[]OptionSchema{
+					{Name: optionMetrics, Type: typeString, Default: "name,stars,watchers,forks,open_issues", Description: "Columns displayed, separated by commas."},
+					{Name: optionOrder, Type: typeString, Default: "pushed", Description: "Order of the repositories (created, updated, pushed or full_name)."},
+				},
+				githubRowLimitOptions,
+				tableOptions,
+				cacheOptions,
+			),
+			widgetSchema(githubTableBranches, titleOption(" Github Branches "), githubRepositoryOptions, githubRowLimitOptions, tableOptions, cacheOptions),
+			widgetSchema(githubTableIssues, titleOption(" Github Issues "), githubRepositoryOptions, githubRowLimitOptions, tableOptions, cacheOptions),
+			widgetSchema(githubTablePullRequests, titleOption(" Github Pull Requests "), githubRepositoryOptions, githubRowLimitOptions, tableOptions, cacheOptions),
+			widgetSchema(githubBarViews, titleOption(" Github Views "), githubRepositoryOptions, barChartOptions, cacheOptions),
+			widgetSchema(
+				githubBarCommits,
+				titleOption(" Github Commit Per Week "),
+				githubRepositoryOptions,
+				dateOptions("7_weeks_ago", "0_weeks_ago"),
+				[]OptionSchema{
+					{Name: optionScope, Type: typeString, Default: ownerScope, Description: "Commits counted: the ones of the owner (owner) or every commit (all)."},
+				},
+				barChartOptions,
+				cacheOptions,
+			),
+			widgetSchema(githubBarStars, titleOption(" Github Stars "), githubRepositoryOptions, dateOptions("7_days_ago", "today"), barChartOptions, cacheOptions),
+		},
+		New: func(config map[string]string) (service, error) {
+			g, err := NewGithubWidget(config["token"], config["owner"], config["repository"])
+			if err != nil {
+				return nil, err
+			}
+
+			g.cache, err = newServiceCache("github", config)
+			return g, err
+		},
+		Doctor: githubDoctor,
+	})
+}
+
+var (
+	githubRepositoryOptions = []OptionSchema{
+		{Name: optionRepository, Type: typeString, Description: "Repository displayed. The repository of the service by default."},
+	}
+
+	githubRowLimitOptions = []OptionSchema{
+		{Name: optionRowLimit, Type: typeInt, Default: "5", Description: "Maximum number of rows."},
+	}
+)
+
 type githubWidget struct {
 	tui    *Tui
 	client *platform.Github
+	cache  serviceCache
 }
 
 // NewGithubWidget with all information necessary to connect to the Github API.
@@ -40,30 +108,30 @@ func NewGithubWidget(token string, owner string, repo string) (*githubWidget, er
 }
 
 // CreateWidgets for the Github service.
-func (g *githubWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (g *githubWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
 
 	switch widget.Name {
 	case githubBoxStars:
-		f, err = g.boxStars(widget)
+		f, err = g.boxStars(ctx, widget)
 	case githubBoxWatchers:
-		f, err = g.boxWatchers(widget)
+		f, err = g.boxWatchers(ctx, widget)
 	case githubBoxOpenIssues:
-		f, err = g.boxOpenIssues(widget)
+		f, err = g.boxOpenIssues(ctx, widget)
 	case githubTableRepositories:
-		f, err = g.tableRepo(widget)
+		f, err = g.tableRepo(ctx, widget)
 	case githubTableBranches:
-		f, err = g.tableBranches(widget)
+		f, err = g.tableBranches(ctx, widget)
 	case githubTableIssues:
-		f, err = g.tableIssues(widget)
+		f, err = g.tableIssues(ctx, widget)
 	case githubTablePullRequests:
-		f, err = g.tablePullRequests(widget)
+		f, err = g.tablePullRequests(ctx, widget)
 	case githubBarViews:
-		f, err = g.barViews(widget)
+		f, err = g.barViews(ctx, widget)
 	case githubBarCommits:
-		f, err = g.barCommits(widget)
+		f, err = g.barCommits(ctx, widget)
 	case githubBarStars:
-		f, err = g.barStars(widget)
+		f, err = g.barStars(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service github", widget.Name)
 	}
@@ -71,7 +139,7 @@ func (g *githubWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, e
 	return
 }
 
-func (g *githubWidget) boxStars(widget Widget) (f func() error, err error) {
+func (g *githubWidget) boxStars(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
@@ -82,7 +150,11 @@ func (g *githubWidget) boxStars(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	stars, err := g.client.TotalStars(repo)
+	var stars int
+	err = g.cache.fetch(ctx, widget, "total_stars", repo, &stars, func() (err error) {
+		stars, err = g.client.TotalStars(ctx, repo)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -100,18 +172,19 @@ func (g *githubWidget) boxStars(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (g *githubWidget) boxWatchers(widget Widget) (f func() error, err error) {
-	title := " Github Watchers "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (g *githubWidget) boxWatchers(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	w, err := g.client.TotalWatchers(repo)
+	var w int
+	err = g.cache.fetch(ctx, widget, "total_watchers", repo, &w, func() (err error) {
+		w, err = g.client.TotalWatchers(ctx, repo)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -129,18 +202,19 @@ func (g *githubWidget) boxWatchers(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (g *githubWidget) boxOpenIssues(widget Widget) (f func() error, err error) {
-	title := " Github Open Issues "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (g *githubWidget) boxOpenIssues(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	w, err := g.client.TotalOpenIssues(repo)
+	var w int
+	err = g.cache.fetch(ctx, widget, "total_open_issues", repo, &w, func() (err error) {
+		w, err = g.client.TotalOpenIssues(ctx, repo)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -158,33 +232,22 @@ func (g *githubWidget) boxOpenIssues(widget Widget) (f func() error, err error)
 	return
 }
 
-func (g *githubWidget) tableRepo(widget Widget) (f func() error, err error) {
-	title := " Github Repositories "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
-
-	var limit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
-	}
+func (g *githubWidget) tableRepo(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	metrics := []string{"name", "stars", "watchers", "forks", "open_issues"}
-	if _, ok := widget.Options[optionMetrics]; ok {
-		if len(widget.Options[optionMetrics]) > 0 {
-			metrics = strings.Split(strings.TrimSpace(widget.Options[optionMetrics]), ",")
-		}
+	limit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	order := "pushed"
-	if _, ok := widget.Options[optionOrder]; ok {
-		order = widget.Options[optionOrder]
-	}
+	metrics := strings.Split(strings.TrimSpace(widget.option(optionMetrics)), ",")
+	order := widget.option(optionOrder)
 
-	rs, err := g.client.ListRepo(int(limit), order, metrics)
+	var rs [][]string
+	err = g.cache.fetch(ctx, widget, "list_repo", []interface{}{limit, order, metrics}, &rs, func() (err error) {
+		rs, err = g.client.ListRepo(ctx, int(limit), order, metrics)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -196,26 +259,24 @@ func (g *githubWidget) tableRepo(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (g *githubWidget) tableBranches(widget Widget) (f func() error, err error) {
+func (g *githubWidget) tableBranches(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Branches "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	var limit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
+	limit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	bs, err := g.client.ListBranches(repo, int(limit))
+	var bs [][]string
+	err = g.cache.fetch(ctx, widget, "list_branches", []interface{}{repo, limit}, &bs, func() (err error) {
+		bs, err = g.client.ListBranches(ctx, repo, int(limit))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -228,117 +289,105 @@ func (g *githubWidget) tableBranches(widget Widget) (f func() error, err error)
 }
 
 // TODO can filter by open or close issue?
-func (g *githubWidget) tableIssues(widget Widget) (f func() error, err error) {
+func (g *githubWidget) tableIssues(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Issues "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	var limit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
+	limit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	is, err := g.client.ListIssues(repo, int(limit))
+	var is linkedTable
+	err = g.cache.fetch(ctx, widget, "list_issues", []interface{}{repo, limit}, &is, func() (err error) {
+		is.Rows, is.URLs, err = g.client.ListIssues(ctx, repo, int(limit))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddTable(is, title, widget.Options)
+		return g.tui.AddTableWithURLs(is.Rows, is.URLs, title, widget.Options)
 	}
 
 	return
 }
 
-func (g *githubWidget) tablePullRequests(widget Widget) (f func() error, err error) {
+func (g *githubWidget) tablePullRequests(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Pull Requests "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	var limit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
+	limit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	is, err := g.client.ListPullRequests(repo, int(limit))
+	var is linkedTable
+	err = g.cache.fetch(ctx, widget, "list_pull_requests", []interface{}{repo, limit}, &is, func() (err error) {
+		is.Rows, is.URLs, err = g.client.ListPullRequests(ctx, repo, int(limit))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddTable(is, title, widget.Options)
+		return g.tui.AddTableWithURLs(is.Rows, is.URLs, title, widget.Options)
 	}
 
 	return
 }
 
-func (g *githubWidget) barViews(widget Widget) (f func() error, err error) {
+func (g *githubWidget) barViews(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Views "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	dim, counts, err := g.client.Views(repo, 0)
+	var res struct {
+		Dim    []string
+		Counts []int
+	}
+	err = g.cache.fetch(ctx, widget, "views", repo, &res, func() (err error) {
+		res.Dim, res.Counts, err = g.client.Views(ctx, repo, 0)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+		return g.tui.AddBarChart(res.Counts, res.Dim, title, widget.Options)
 	}
 
 	return
 }
 
 // TODO to refactor - transforming any date statement (weeks_ago, month_ago) into days weeks_ago in platform.date, and plugt it in.
-func (g *githubWidget) barCommits(widget Widget) (f func() error, err error) {
+func (g *githubWidget) barCommits(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Commit Per Week "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	sd := "7_weeks_ago"
-	if _, ok := widget.Options[optionStartDate]; ok {
-		sd = widget.Options[optionStartDate]
-	}
+	sd := widget.option(optionStartDate)
 
-	ed := "0_weeks_ago"
-	if _, ok := widget.Options[optionEndDate]; ok {
-		ed = widget.Options[optionEndDate]
-	}
+	ed := widget.option(optionEndDate)
 
-	scope := ownerScope
-	if _, ok := widget.Options[optionScope]; ok {
-		scope = widget.Options[optionScope]
-	}
+	scope := widget.option(optionScope)
 
 	if !strings.Contains(sd, "weeks_ago") || !strings.Contains(ed, "weeks_ago") {
 		return nil, errors.New("The widget github.bar_commits require you to indicate a week range, ie startDate: 5_weeks_ago, endDate: 1_weeks_ago ")
@@ -354,52 +403,105 @@ func (g *githubWidget) barCommits(widget Widget) (f func() error, err error) {
 		return nil, err
 	}
 
-	dim, counts, err := g.client.CountCommits(repo, scope, sw, ew, time.Now())
+	now := time.Now()
+	var res struct {
+		Dim    []string
+		Counts []int
+	}
+	params := []interface{}{repo, scope, sw, ew, now.Format("2006-01-02")}
+	err = g.cache.fetch(ctx, widget, "count_commits", params, &res, func() (err error) {
+		res.Dim, res.Counts, err = g.client.CountCommits(ctx, repo, scope, sw, ew, now)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+		return g.tui.AddBarChart(res.Counts, res.Dim, title, widget.Options)
 	}
 
 	return
 }
 
-func (g *githubWidget) barStars(widget Widget) (f func() error, err error) {
+func (g *githubWidget) barStars(ctx context.Context, widget Widget) (f func() error, err error) {
 	var repo string
 	if _, ok := widget.Options[optionRepository]; ok {
 		repo = widget.Options[optionRepository]
 	}
 
-	title := " Github Stars "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
-	startDate := "7_days_ago"
-	if _, ok := widget.Options[optionStartDate]; ok {
-		startDate = widget.Options[optionStartDate]
-	}
+	startDate := widget.option(optionStartDate)
 
-	endDate := "today"
-	if _, ok := widget.Options[optionEndDate]; ok {
-		endDate = widget.Options[optionEndDate]
-	}
+	endDate := widget.option(optionEndDate)
 
 	sd, ed, err := platform.ConvertDates(time.Now(), startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	dim, counts, err := g.client.CountStars(repo, sd, ed)
+	var res struct {
+		Dim    []string
+		Counts []int
+	}
+	params := []interface{}{repo, sd.Format("2006-01-02"), ed.Format("2006-01-02")}
+	err = g.cache.fetch(ctx, widget, "count_stars", params, &res, func() (err error) {
+		res.Dim, res.Counts, err = g.client.CountStars(ctx, repo, sd, ed)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+		return g.tui.AddBarChart(res.Counts, res.Dim, title, widget.Options)
 	}
 
 	return
 }
+
+// githubDoctor check the token of Github, its scopes, and the access to the repository of the service.
+func githubDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add(
+		"token is set",
+		requireOption(config, "token"),
+		"Create a personal access token on https://github.com/settings/tokens, and set the option token of the service or the environment variable DEVDASH_GITHUB_TOKEN.",
+	) {
+		return d
+	}
+
+	g, err := platform.NewGithubClient(config["token"], config["owner"], config["repository"])
+	if !d.add("create the client", err, "") {
+		return d
+	}
+
+	scopes, err := g.TokenScopes(ctx)
+	hint := "Check your connection to https://api.github.com."
+	if platform.IsUnauthorized(err) {
+		hint = "The token is expired or revoked: create a new one on https://github.com/settings/tokens."
+	}
+	if !d.add("token is valid", err, hint) {
+		return d
+	}
+
+	// The fine-grained tokens don't have scopes: their permissions are checked with the repository.
+	err = nil
+	if len(scopes) > 0 && !contains(scopes, "repo") && !contains(scopes, "public_repo") {
+		err = errors.Errorf("the scopes of the token are %s", strings.Join(scopes, ", "))
+	}
+	if !d.add("token has the scope repo or public_repo", err, "Add the scope repo to the token, or public_repo if every repository is public.") {
+		return d
+	}
+
+	if config["repository"] != "" {
+		d.add(
+			"access to the repository "+config["owner"]+"/"+config["repository"],
+			g.CheckRepository(ctx, ""),
+			"Check the options owner and repository of the service. The private repositories need the scope repo.",
+		)
+	}
+
+	return d
+}
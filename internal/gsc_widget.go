@@ -1,8 +1,8 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -20,10 +20,53 @@ const (
 	gscTimeFormat = "2006-01-02"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "gsc",
+		Name:      "Google Search Console",
+		ConfigKey: "google_search_console",
+		Options: []ServiceOption{
+			{Name: "keyfile", Env: "DEVDASH_GSC_KEYFILE"},
+			{Name: "address"},
+			{Name: optionCacheTTL},
+		},
+		Widgets: []WidgetSchema{
+			widgetSchema(gscTablePages, withoutOptions(gscTableOptions, optionDimension)),
+			widgetSchema(gscTableQueries, gscTableOptions),
+			widgetSchema(gscTable, gscTableOptions),
+		},
+		New: func(config map[string]string) (service, error) {
+			s, err := NewGscWidget(config["keyfile"], config["address"])
+			if err != nil {
+				return nil, err
+			}
+
+			s.cache, err = newServiceCache("gsc", config)
+			return s, err
+		},
+		Doctor: gscDoctor,
+	})
+}
+
+var gscTableOptions = joinOptions(
+	titleOption(""),
+	[]OptionSchema{
+		{Name: optionDimension, Type: typeString, Default: "query", Description: "Dimension of the first column (query or page)."},
+		{Name: optionMetrics, Type: typeString, Default: "clicks,impressions,ctr,position", Description: "Metrics displayed, separated by commas."},
+		{Name: optionFilters, Type: typeString, Description: "Filter of the first column."},
+		{Name: optionRowLimit, Type: typeInt, Default: "5", Description: "Maximum number of rows."},
+		{Name: optionCharLimit, Type: typeInt, Default: "1000", Description: "Maximum number of characters of the first column."},
+	},
+	dateOptions("7_days_ago", "today"),
+	tableOptions,
+	cacheOptions,
+)
+
 type gscWidget struct {
 	tui     *Tui
 	client  *platform.SearchConsole
 	address string
+	cache   serviceCache
 }
 
 var mappingGscHeader = map[string]string{
@@ -45,15 +88,15 @@ func NewGscWidget(keyfile string, address string) (*gscWidget, error) {
 }
 
 // CreateWidgets for the Google Search Console API.
-func (s *gscWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (s *gscWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	s.tui = tui
 	switch widget.Name {
 	case gscTablePages:
-		f, err = s.pages(widget)
+		f, err = s.pages(ctx, widget)
 	case gscTableQueries:
-		f, err = s.table(widget)
+		f, err = s.table(ctx, widget)
 	case gscTable:
-		f, err = s.table(widget)
+		f, err = s.table(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s", widget.Name)
 	}
@@ -61,60 +104,44 @@ func (s *gscWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err
 	return
 }
 
-func (s *gscWidget) pages(widget Widget) (f func() error, err error) {
-	if widget.Options == nil {
-		widget.Options = map[string]string{}
-	}
-
-	widget.Options[optionMetric] = "page"
+func (s *gscWidget) pages(ctx context.Context, widget Widget) (f func() error, err error) {
+	widget.Options = pagesOptions(widget.Options)
 
-	return s.table(widget)
+	return s.table(ctx, widget)
 }
 
-// table of the result of a Google Search Console query.
-// If no metric provided, the default is "query" with no filters.
-func (s *gscWidget) table(widget Widget) (f func() error, err error) {
-	sd := "7_days_ago"
-	if _, ok := widget.Options[optionStartDate]; ok {
-		sd = widget.Options[optionStartDate]
+// pagesOptions of the query of a table of pages: the options of the widget, with the pages as dimension.
+func pagesOptions(options map[string]string) map[string]string {
+	o := make(map[string]string, len(options)+1)
+	for k, v := range options {
+		o[k] = v
 	}
+	o[optionDimension] = "page"
 
-	ed := "today"
-	if _, ok := widget.Options[optionEndDate]; ok {
-		ed = widget.Options[optionEndDate]
-	}
+	return o
+}
 
+// table of the result of a Google Search Console query.
+// If no metric provided, the default is "query" with no filters.
+func (s *gscWidget) table(ctx context.Context, widget Widget) (f func() error, err error) {
+	sd, ed := widget.option(optionStartDate), widget.option(optionEndDate)
 	startDate, endDate, err := platform.ConvertDates(time.Now(), sd, ed)
 	if err != nil {
 		return nil, err
 	}
 
-	var rowLimit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		rowLimit, err = strconv.ParseInt(widget.Options[optionRowLimit], 0, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
-	}
-
-	charLimit := 1000
-	if _, ok := widget.Options[optionCharLimit]; ok {
-		c, err := strconv.ParseInt(widget.Options[optionCharLimit], 0, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionCharLimit])
-		}
-		charLimit = int(c)
+	rowLimit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	dimension := "query"
-	if _, ok := widget.Options[optionDimension]; ok {
-		dimension = widget.Options[optionDimension]
+	charLimit, err := widget.intOption(optionCharLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	filters := ""
-	if _, ok := widget.Options[optionFilters]; ok {
-		filters = widget.Options[optionFilters]
-	}
+	dimension := widget.option(optionDimension)
+	filters := widget.option(optionFilters)
 
 	metrics := []string{"clicks", "impressions", "ctr", "position"}
 	if _, ok := widget.Options[optionMetrics]; ok {
@@ -133,23 +160,22 @@ func (s *gscWidget) table(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	results, err := s.client.Table(
-		startDate.Format(gscTimeFormat),
-		endDate.Format(gscTimeFormat),
-		rowLimit,
-		s.address,
-		dimension,
-		filters,
-	)
+	start, end := startDate.Format(gscTimeFormat), endDate.Format(gscTimeFormat)
+	var results []platform.SearchConsoleResponse
+	params := []interface{}{start, end, rowLimit, s.address, dimension, filters}
+	err = s.cache.fetch(ctx, widget, "table", params, &results, func() (err error) {
+		results, err = s.client.Table(ctx, start, end, rowLimit, s.address, dimension, filters)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	table := formatNumerics(results, dimension, metrics)
-	table = formatText(table, charLimit, s.address)
+	table = formatText(table, int(charLimit), s.address)
 
 	f = func() error {
-		return s.tui.AddTable(table, title, widget.Options)
+		return s.tui.AddTableWithURLs(table, pageURLs(results, dimension), title, widget.Options)
 	}
 
 	return
@@ -181,6 +207,20 @@ func formatNumerics(results []platform.SearchConsoleResponse, dimension string,
 	return table
 }
 
+// pageURLs of the results, if they are pages.
+func pageURLs(results []platform.SearchConsoleResponse, dimension string) []string {
+	if dimension != "page" {
+		return nil
+	}
+
+	urls := make([]string, len(results))
+	for k, v := range results {
+		urls[k] = v.Dimension
+	}
+
+	return urls
+}
+
 func formatText(table [][]string, charLimit int, trimPrefix string) [][]string {
 	// Begins the loop to 1 not to shorten the headers.
 	for i := 1; i < len(table); i++ {
@@ -195,3 +235,32 @@ func formatText(table [][]string, charLimit int, trimPrefix string) [][]string {
 
 	return table
 }
+
+// gscDoctor check the keyfile of Google Search Console and the access to the property.
+func gscDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add("keyfile is set", requireOption(config, "keyfile"), "Set the option keyfile of the service, or the environment variable DEVDASH_GSC_KEYFILE.") {
+		return d
+	}
+
+	sc, err := platform.NewSearchConsoleClient(config["keyfile"])
+	if !d.add(
+		"keyfile is the key of a service account",
+		err,
+		"Create a JSON key for a service account in the Google Cloud console. The keyfile is searched in the current directory, then in $XDG_CONFIG_HOME/devdash.",
+	) {
+		return d
+	}
+
+	if !d.add("address is set", requireOption(config, "address"), "Set the option address of the service, with the URL of the property (for example https://example.com/).") {
+		return d
+	}
+
+	d.add(
+		"access to the property "+config["address"],
+		sc.CheckSite(ctx, config["address"]),
+		"Add the email of the service account (client_email in the keyfile) to the users of the property in Search Console. The address needs to be the exact URL of the property.",
+	)
+
+	return d
+}
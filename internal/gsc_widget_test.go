@@ -83,3 +83,41 @@ func Test_formatNumerics(t *testing.T) {
 		})
 	}
 }
+
+func Test_pagesOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "no option",
+			options:  nil,
+			expected: map[string]string{optionDimension: "page"},
+		},
+		{
+			name:    "metrics kept",
+			options: map[string]string{optionMetrics: "clicks,ctr", optionRowLimit: "10"},
+			expected: map[string]string{
+				optionDimension: "page",
+				optionMetrics:   "clicks,ctr",
+				optionRowLimit:  "10",
+			},
+		},
+		{
+			name:     "dimension replaced",
+			options:  map[string]string{optionDimension: "query"},
+			expected: map[string]string{optionDimension: "page"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := pagesOptions(tc.options)
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
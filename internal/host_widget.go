@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -31,23 +32,118 @@ const (
 	rhBar           = "rh.bar"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "rh",
+		Name:      "Remote Host",
+		ConfigKey: "remote_host",
+		Options: []ServiceOption{
+			{Name: "username"},
+			{Name: "address"},
+		},
+		Widgets: hostWidgets,
+		New: func(config map[string]string) (service, error) {
+			return NewHostWidget(config["username"], config["address"]), nil
+		},
+		Doctor: hostDoctor,
+	})
+
+	RegisterService(ServiceDefinition{
+		ID:      "lh",
+		Name:    "Localhost",
+		Widgets: hostWidgets,
+		New: func(map[string]string) (service, error) {
+			return NewHostWidget("localhost", "localhost"), nil
+		},
+	})
+}
+
+var hostUnitOptions = []OptionSchema{
+	{Name: optionUnit, Type: typeString, Default: "kb", Description: "Unit of the data (kb, mb, gb or tb)."},
+}
+
+// hostWidgets of the remote hosts and of the localhost.
+var hostWidgets = []WidgetSchema{
+	widgetSchema(rhUptime, titleOption(" Uptime "), textBoxOptions),
+	widgetSchema(rhLoad, titleOption(" Load "), textBoxOptions),
+	widgetSchema(rhProcesses, titleOption(" Running processes "), textBoxOptions),
+	widgetSchema(rhBoxMemRate, titleOption(" Memory usage "), textBoxOptions),
+	widgetSchema(rhGaugeMemRate, titleOption(" Memory usage "), gaugeOptions),
+	widgetSchema(rhBoxSwapRate, titleOption(" Swap usage "), textBoxOptions),
+	widgetSchema(rhGaugeSwapRate, titleOption(" Swap usage "), gaugeOptions),
+	widgetSchema(rhBoxNetIO, titleOption(""), hostUnitOptions, textBoxOptions),
+	widgetSchema(rhBoxDiskIO, titleOption(""), hostUnitOptions, textBoxOptions),
+	widgetSchema(rhBoxCPURate, titleOption(" CPU usage "), textBoxOptions),
+	widgetSchema(rhGaugeCPURate, titleOption(" CPU usage "), gaugeOptions),
+	widgetSchema(
+		rhBarMemory,
+		titleOption(""),
+		[]OptionSchema{
+			{Name: optionMetrics, Type: typeString, Default: "MemTotal,MemFree,MemAvailable", Description: "Fields of /proc/meminfo displayed, separated by commas."},
+			{Name: optionHeaders, Type: typeString, Description: "Headers of the bars, separated by commas. The metrics by default."},
+		},
+		hostUnitOptions,
+		barChartOptions,
+	),
+	widgetSchema(rhBarRates, titleOption(" Resources usage (%) "), barChartOptions),
+	widgetSchema(
+		rhTableDisk,
+		titleOption(" Disks "),
+		[]OptionSchema{
+			{Name: optionUnit, Type: typeString, Default: "gb", Description: "Unit of the data (kb, mb, gb or tb)."},
+			{Name: optionHeaders, Type: typeString, Default: "Filesystem,Size,Used,Available,Use%,Mount", Description: "Headers of the columns, separated by commas."},
+		},
+		tableOptions,
+	),
+	widgetSchema(
+		rhTable,
+		titleOption(" Table "),
+		[]OptionSchema{
+			{Name: optionCommand, Type: typeString, Default: "/bin/df -x devtmpfs -x tmpfs -x debugfs | tail -n +2", Description: "Command displaying the rows, one per line."},
+			{Name: optionHeaders, Type: typeString, Description: "Headers of the columns, separated by commas."},
+		},
+		tableOptions,
+	),
+	widgetSchema(
+		rhBox,
+		titleOption(" Box "),
+		[]OptionSchema{{Name: optionCommand, Type: typeString, Default: "echo 'box'", Description: "Command displaying the content of the box."}},
+		textBoxOptions,
+	),
+	widgetSchema(
+		rhGauge,
+		titleOption(" Gauge "),
+		[]OptionSchema{{Name: optionCommand, Type: typeString, Default: "echo 50", Description: "Command displaying the percentage of the gauge."}},
+		gaugeOptions,
+	),
+	widgetSchema(
+		rhBar,
+		titleOption(" Example of bar "),
+		[]OptionSchema{
+			{Name: optionCommand, Type: typeString, Default: "echo -e 20 30 40 50", Description: "Command displaying the values of the bars, separated by spaces."},
+			{Name: optionHeaders, Type: typeString, Default: "small,bigger,big,insane", Description: "Headers of the bars, separated by commas."},
+		},
+		barChartOptions,
+	),
+}
+
 type HostWidget struct {
 	tui     *Tui
 	service *platform.Host
 }
 
-func NewHostWidget(username, addr string) (*HostWidget, error) {
-	service, err := platform.NewHost(username, addr)
-	if err != nil {
-		return nil, err
+func NewHostWidget(username, addr string) *HostWidget {
+	return &HostWidget{
+		service: platform.NewHost(username, addr),
 	}
+}
 
-	return &HostWidget{
-		service: service,
-	}, nil
+// Close the connection to the host.
+func (ms *HostWidget) Close() error {
+	return ms.service.Close()
 }
 
-func (ms *HostWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (ms *HostWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	ms.tui = tui
 
 	// Compatibility with localhost
@@ -55,54 +151,51 @@ func (ms *HostWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, er
 
 	switch name {
 	case rhUptime:
-		f, err = ms.boxUptime(widget)
+		f, err = ms.boxUptime(ctx, widget)
 	case rhLoad:
-		f, err = ms.boxLoad(widget)
+		f, err = ms.boxLoad(ctx, widget)
 	case rhProcesses:
-		f, err = ms.boxProcesses(widget)
+		f, err = ms.boxProcesses(ctx, widget)
 	case rhBarMemory:
-		f, err = ms.barMemory(widget)
+		f, err = ms.barMemory(ctx, widget)
 	case rhBoxCPURate:
-		f, err = ms.boxCPURate(widget)
+		f, err = ms.boxCPURate(ctx, widget)
 	case rhGaugeCPURate:
-		f, err = ms.gaugeCPURate(widget)
+		f, err = ms.gaugeCPURate(ctx, widget)
 	case rhBoxMemRate:
-		f, err = ms.boxMemRate(widget)
+		f, err = ms.boxMemRate(ctx, widget)
 	case rhGaugeMemRate:
-		f, err = ms.gaugeMemRate(widget)
+		f, err = ms.gaugeMemRate(ctx, widget)
 	case rhBoxSwapRate:
-		f, err = ms.boxSwapRate(widget)
+		f, err = ms.boxSwapRate(ctx, widget)
 	case rhGaugeSwapRate:
-		f, err = ms.gaugeSwapRate(widget)
+		f, err = ms.gaugeSwapRate(ctx, widget)
 	case rhBoxNetIO:
-		f, err = ms.boxNetIO(widget)
+		f, err = ms.boxNetIO(ctx, widget)
 	case rhBoxDiskIO:
-		f, err = ms.boxDiskIO(widget)
+		f, err = ms.boxDiskIO(ctx, widget)
 	case rhBarRates:
-		f, err = ms.barRates(widget)
+		f, err = ms.barRates(ctx, widget)
 	case rhTableDisk:
-		f, err = ms.tableDisk(widget)
+		f, err = ms.tableDisk(ctx, widget)
 	case rhTable:
-		f, err = ms.table(widget)
+		f, err = ms.table(ctx, widget)
 	case rhBox:
-		f, err = ms.box(widget)
+		f, err = ms.box(ctx, widget)
 	case rhGauge:
-		f, err = ms.gauge(widget)
+		f, err = ms.gauge(ctx, widget)
 	case rhBar:
-		f, err = ms.bar(widget)
+		f, err = ms.bar(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s", widget.Name)
 	}
 	return
 }
 
-func (ms *HostWidget) boxLoad(widget Widget) (f func() error, err error) {
-	title := " Load "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxLoad(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	load, err := platform.HostLoad(ms.service.Runner)
+	load, err := platform.HostLoad(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -114,13 +207,10 @@ func (ms *HostWidget) boxLoad(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxProcesses(widget Widget) (f func() error, err error) {
-	title := " Running processes "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxProcesses(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	procs, err := platform.HostProcesses(ms.service.Runner)
+	procs, err := platform.HostProcesses(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -132,13 +222,10 @@ func (ms *HostWidget) boxProcesses(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxUptime(widget Widget) (f func() error, err error) {
-	title := " Uptime "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxUptime(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	uptime, err := platform.HostUptime(ms.service.Runner)
+	uptime, err := platform.HostUptime(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -176,13 +263,10 @@ func formatSeconds(dur time.Duration) string {
 	return s2
 }
 
-func (ms *HostWidget) boxCPURate(widget Widget) (f func() error, err error) {
-	title := " CPU usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxCPURate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	CPURate, err := platform.HostCPURate(ms.service.Runner)
+	CPURate, err := platform.HostCPURate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -194,13 +278,10 @@ func (ms *HostWidget) boxCPURate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) gaugeCPURate(widget Widget) (f func() error, err error) {
-	title := " CPU usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) gaugeCPURate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	CPURate, err := platform.HostCPURate(ms.service.Runner)
+	CPURate, err := platform.HostCPURate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -212,13 +293,10 @@ func (ms *HostWidget) gaugeCPURate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxMemRate(widget Widget) (f func() error, err error) {
-	title := " Memory usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxMemRate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	memRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memRate, err := platform.HostMemoryRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -230,13 +308,10 @@ func (ms *HostWidget) boxMemRate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) gaugeMemRate(widget Widget) (f func() error, err error) {
-	title := " Memory usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) gaugeMemRate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	memRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memRate, err := platform.HostMemoryRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -248,13 +323,10 @@ func (ms *HostWidget) gaugeMemRate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxSwapRate(widget Widget) (f func() error, err error) {
-	title := " Swap usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) boxSwapRate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -266,13 +338,10 @@ func (ms *HostWidget) boxSwapRate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) gaugeSwapRate(widget Widget) (f func() error, err error) {
-	title := " Swap usage "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) gaugeSwapRate(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -284,23 +353,20 @@ func (ms *HostWidget) gaugeSwapRate(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) barRates(widget Widget) (f func() error, err error) {
-	title := " Resources usage (%) "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) barRates(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	cpuRate, err := platform.HostCPURate(ms.service.Runner)
+	cpuRate, err := platform.HostCPURate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	memoryRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memoryRate, err := platform.HostMemoryRate(ms.service.Runner(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -317,18 +383,15 @@ func (ms *HostWidget) barRates(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxNetIO(widget Widget) (f func() error, err error) {
-	unit := "kb"
-	if _, ok := widget.Options[optionUnit]; ok {
-		unit = widget.Options[optionUnit]
-	}
+func (ms *HostWidget) boxNetIO(ctx context.Context, widget Widget) (f func() error, err error) {
+	unit := widget.option(optionUnit)
 
 	title := fmt.Sprintf(" Net I/O (%s) ", strings.ToUpper(unit))
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	netIO, err := platform.HostNetIO(ms.service.Runner, unit)
+	netIO, err := platform.HostNetIO(ms.service.Runner(ctx), unit)
 	if err != nil {
 		return nil, err
 	}
@@ -340,18 +403,15 @@ func (ms *HostWidget) boxNetIO(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) boxDiskIO(widget Widget) (f func() error, err error) {
-	unit := "kb"
-	if _, ok := widget.Options[optionUnit]; ok {
-		unit = widget.Options[optionUnit]
-	}
+func (ms *HostWidget) boxDiskIO(ctx context.Context, widget Widget) (f func() error, err error) {
+	unit := widget.option(optionUnit)
 
 	title := fmt.Sprintf(" Disk I/O (%s) ", strings.ToUpper(unit))
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	diskIO, err := platform.HostDiskIO(ms.service.Runner, unit)
+	diskIO, err := platform.HostDiskIO(ms.service.Runner(ctx), unit)
 	if err != nil {
 		return nil, err
 	}
@@ -363,7 +423,7 @@ func (ms *HostWidget) boxDiskIO(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
+func (ms *HostWidget) barMemory(ctx context.Context, widget Widget) (f func() error, err error) {
 	metrics := []string{"MemTotal", "MemFree", "MemAvailable"}
 	if _, ok := widget.Options[optionMetrics]; ok {
 		if len(widget.Options[optionMetrics]) > 0 {
@@ -378,17 +438,14 @@ func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	unit := "kb"
-	if _, ok := widget.Options[optionUnit]; ok {
-		unit = widget.Options[optionUnit]
-	}
+	unit := widget.option(optionUnit)
 
 	title := fmt.Sprintf(" Memory (%s) ", strings.ToUpper(unit))
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	mem, err := platform.HostMemory(ms.service.Runner, metrics, unit)
+	mem, err := platform.HostMemory(ms.service.Runner(ctx), metrics, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -400,16 +457,10 @@ func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
-	unit := "gb"
-	if _, ok := widget.Options[optionUnit]; ok {
-		unit = widget.Options[optionUnit]
-	}
+func (ms *HostWidget) tableDisk(ctx context.Context, widget Widget) (f func() error, err error) {
+	unit := widget.option(optionUnit)
 
-	title := fmt.Sprintf(" Disks ")
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
 	headers := []string{"Filesystem", "Size", "Used", "Available", "Use%", "Mount"}
 	if _, ok := widget.Options[optionHeaders]; ok {
@@ -418,7 +469,7 @@ func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	data, err := platform.HostDisk(ms.service.Runner, headers, unit)
+	data, err := platform.HostDisk(ms.service.Runner(ctx), headers, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -430,17 +481,13 @@ func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
-	title := fmt.Sprintf(" Table ")
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) table(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
 	headers := []string{"Filesystem", "Size", "Used", "Available", "Use%", "Mount"}
 
-	cmd := "/bin/df -x devtmpfs -x tmpfs -x debugfs | tail -n +2"
+	cmd := widget.option(optionCommand)
 	if _, ok := widget.Options[optionCommand]; ok {
-		cmd = widget.Options[optionCommand]
 		headers = []string{}
 	}
 
@@ -450,7 +497,7 @@ func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	data, err := platform.HostTable(ms.service.Runner, cmd, headers)
+	data, err := platform.HostTable(ms.service.Runner(ctx), cmd, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -462,18 +509,12 @@ func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) box(widget Widget) (f func() error, err error) {
-	title := fmt.Sprintf(" Box ")
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) box(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	cmd := "echo 'box'"
-	if _, ok := widget.Options[optionCommand]; ok {
-		cmd = widget.Options[optionCommand]
-	}
+	cmd := widget.option(optionCommand)
 
-	data, err := platform.HostBox(ms.service.Runner, cmd)
+	data, err := platform.HostBox(ms.service.Runner(ctx), cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -485,19 +526,12 @@ func (ms *HostWidget) box(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) gauge(widget Widget) (f func() error, err error) {
-	title := fmt.Sprintf(" Gauge ")
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) gauge(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
-	cmd := "echo 50"
-	// cmd := "/bin/df -x devtmpfs -x tmpfs -x debugfs | sed -n '1!p'"
-	if _, ok := widget.Options[optionCommand]; ok {
-		cmd = widget.Options[optionCommand]
-	}
+	cmd := widget.option(optionCommand)
 
-	data, err := platform.HostGauge(ms.service.Runner, cmd)
+	data, err := platform.HostGauge(ms.service.Runner(ctx), cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -509,11 +543,8 @@ func (ms *HostWidget) gauge(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
-	title := " Example of bar "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (ms *HostWidget) bar(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
 	headers := []string{"small", "bigger", "big", "insane"}
 	if _, ok := widget.Options[optionHeaders]; ok {
@@ -522,12 +553,9 @@ func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	cmd := "echo -e 20 30 40 50"
-	if _, ok := widget.Options[optionCommand]; ok {
-		cmd = widget.Options[optionCommand]
-	}
+	cmd := widget.option(optionCommand)
 
-	data, err := platform.HostBar(ms.service.Runner, cmd)
+	data, err := platform.HostBar(ms.service.Runner(ctx), cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -543,3 +571,35 @@ func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
 
 	return
 }
+
+// hostDoctor check the SSH agent and the SSH connection to the remote host.
+func hostDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	_, err := platform.CheckSSHAgent()
+	if !d.add(
+		"ssh-agent is running with keys",
+		err,
+		"Start ssh-agent (eval $(ssh-agent)) and add your key with ssh-add. $SSH_AUTH_SOCK needs to be set when DevDash is run.",
+	) {
+		return d
+	}
+
+	if !d.add("username is set", requireOption(config, "username"), "Set the option username of the service, with the user to connect with.") {
+		return d
+	}
+
+	if !d.add("address is set", requireOption(config, "address"), "Set the option address of the service, with the host and the port (for example 192.168.1.10:22).") {
+		return d
+	}
+
+	h := platform.NewHost(config["username"], config["address"])
+	if d.add(
+		"SSH handshake with "+config["username"]+"@"+config["address"],
+		h.Connect(),
+		"Check that the host is reachable on this port, and that your public key is authorized for the user on the host (ssh-copy-id).",
+	) {
+		h.Close()
+	}
+
+	return d
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -14,6 +15,27 @@ const (
 	boxAvailability = "mon.box_availability"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "mon",
+		Name:      "Monitor",
+		ConfigKey: "monitor",
+		Options:   []ServiceOption{{Name: "address"}},
+		Widgets: []WidgetSchema{
+			widgetSchema(boxPing, titleOption(" Availability "), monitorAddressOptions, textBoxOptions),
+			widgetSchema(boxAvailability, titleOption(" Availability "), monitorAddressOptions, textBoxOptions),
+		},
+		New: func(config map[string]string) (service, error) {
+			return NewMonitorWidget(config["address"])
+		},
+		Doctor: monitorDoctor,
+	})
+}
+
+var monitorAddressOptions = []OptionSchema{
+	{Name: optionAddress, Type: typeString, Description: "Address monitored. The address of the service by default."},
+}
+
 type monitorWidget struct {
 	tui     *Tui
 	address string
@@ -27,14 +49,14 @@ func NewMonitorWidget(address string) (*monitorWidget, error) {
 }
 
 // CreateWidgets for the monitor service.
-func (m *monitorWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (m *monitorWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	m.tui = tui
 
 	switch widget.Name {
 	case boxPing:
-		f, err = m.pingWidget(widget)
+		f, err = m.pingWidget(ctx, widget)
 	case boxAvailability:
-		f, err = m.availabilityWidget(widget)
+		f, err = m.availabilityWidget(ctx, widget)
 	default:
 		return nil, errors.New("can't find the widget " + widget.Name)
 	}
@@ -42,7 +64,7 @@ func (m *monitorWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error,
 	return
 }
 
-func (m *monitorWidget) pingWidget(widget Widget) (f func() error, err error) {
+func (m *monitorWidget) pingWidget(ctx context.Context, widget Widget) (f func() error, err error) {
 	u := m.address
 
 	if _, ok := widget.Options[optionAddress]; ok {
@@ -59,13 +81,22 @@ func (m *monitorWidget) pingWidget(widget Widget) (f func() error, err error) {
 		return nil, err
 	}
 	pinger.Count = 1
+
+	// Stop pinging if the context is done before the end.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pinger.Stop()
+		case <-done:
+		}
+	}()
+
 	pinger.Run()                 // blocks until finished
 	stats := pinger.Statistics() // get send/receive/rtt stats
 
-	title := " Availability "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
 	f = func() error {
 		return m.tui.AddTextBox(
@@ -78,13 +109,13 @@ func (m *monitorWidget) pingWidget(widget Widget) (f func() error, err error) {
 	return
 }
 
-func (m *monitorWidget) availabilityWidget(widget Widget) (f func() error, err error) {
+func (m *monitorWidget) availabilityWidget(ctx context.Context, widget Widget) (f func() error, err error) {
 	u := m.address
 	if _, ok := widget.Options[optionAddress]; ok {
 		u = widget.Options[optionAddress]
 	}
 
-	req, err := http.NewRequest(http.MethodGet, u, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,10 +136,7 @@ func (m *monitorWidget) availabilityWidget(widget Widget) (f func() error, err e
 		defer res.Body.Close()
 	}
 
-	title := " Availability "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+	title := widget.option(optionTitle)
 
 	f = func() error {
 		return m.tui.AddTextBox(
@@ -120,3 +148,35 @@ func (m *monitorWidget) availabilityWidget(widget Widget) (f func() error, err e
 
 	return
 }
+
+// monitorDoctor check that the address of the service responds.
+func monitorDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if !d.add("address is set", requireOption(config, "address"), "Set the option address of the service, with the URL of your website.") {
+		return d
+	}
+
+	d.add(config["address"]+" responds", checkAvailability(ctx, config["address"]), "Check the address, with its scheme (for example https://example.com), and your connection.")
+
+	return d
+}
+
+// checkAvailability of an address: it needs to respond with the status 200.
+func checkAvailability(ctx context.Context, address string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("status %d", res.StatusCode)
+	}
+
+	return nil
+}
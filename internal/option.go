@@ -0,0 +1,244 @@
+package internal
+
+// Schemas of the options of the widgets.
+// Each widget declares the options it accepts with their type, their default value and a description.
+// The options of a widget are checked against its schema before its data are fetched, and the widgets read
+// their options (or their defaults) with the schema.
+// The same schemas are used to validate a configuration and to document the widgets.
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OptionType of the value of an option.
+type OptionType string
+
+const (
+	typeString OptionType = "string"
+	typeInt    OptionType = "int"
+	typeBool   OptionType = "bool"
+	typeColor  OptionType = "color"
+	typeSize   OptionType = "size"
+	typeAction OptionType = "action"
+)
+
+// OptionSchema describes an option of a widget.
+type OptionSchema struct {
+	Name string
+	Type OptionType
+	// Default value when the option is not set. Empty if the option has no default.
+	Default     string
+	Description string
+}
+
+// WidgetSchema describes a widget and the options it accepts.
+type WidgetSchema struct {
+	// Name of the widget without the ID of its service (for example "bar_sessions").
+	Name    string
+	Options []OptionSchema
+}
+
+// Options shared by the widgets, depending on how they're displayed.
+var (
+	colorOptions = []OptionSchema{
+		{Name: optionColor, Type: typeColor, Description: "Color of the whole widget."},
+		{Name: optionBorderColor, Type: typeColor, Description: "Color of the border."},
+		{Name: optionTitleColor, Type: typeColor, Description: "Color of the title."},
+		{Name: optionTextColor, Type: typeColor, Description: "Color of the text."},
+	}
+
+	textBoxOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionHeight, Type: typeInt, Default: "3", Description: "Height of the widget, in lines."},
+		{Name: optionBold, Type: typeBool, Default: "false", Description: "Display the text in bold."},
+		{Name: optionMultiline, Type: typeBool, Default: "false", Description: "Display the text on multiple lines."},
+	})
+
+	gaugeOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionBarColor, Type: typeColor, Description: "Color of the gauge."},
+		{Name: optionHeight, Type: typeInt, Default: "3", Description: "Height of the widget, in lines."},
+	})
+
+	barChartOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionBarColor, Type: typeColor, Description: "Color of the bars."},
+		{Name: optionNumColor, Type: typeColor, Description: "Color of the numbers in the bars."},
+		{Name: optionEmptyNumColor, Type: typeColor, Description: "Color of the numbers of the empty bars."},
+		{Name: optionHeight, Type: typeInt, Default: "10", Description: "Height of the widget, in lines."},
+		{Name: optionBarGap, Type: typeInt, Default: "0", Description: "Space between the bars."},
+		{Name: optionBarWidth, Type: typeInt, Default: "6", Description: "Width of the bars."},
+	})
+
+	stackedBarChartOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionNumColor, Type: typeColor, Description: "Color of the numbers in the bars."},
+		{Name: optionHeight, Type: typeInt, Default: "10", Description: "Height of the widget, in lines."},
+		{Name: optionBarGap, Type: typeInt, Default: "0", Description: "Space between the bars."},
+		{Name: optionBarWidth, Type: typeInt, Default: "6", Description: "Width of the bars."},
+	})
+
+	tableOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionHeight, Type: typeInt, Default: "0", Description: "Height of the widget, in lines. The table is as high as its rows if 0."},
+		{
+			Name:        optionAction,
+			Type:        typeAction,
+			Description: "Action run on the selected row: open (the default if the rows have URLs), copy, command or none.",
+		},
+		{Name: optionActionCommand, Type: typeString, Description: "Command run by the action \"command\", with $DEVDASH_URL and $DEVDASH_ROW."},
+	})
+
+	projectTitleOptions = joinOptions(colorOptions, []OptionSchema{
+		{Name: optionSize, Type: typeSize, Default: "XXL", Description: "Size of the title (XXS to XXL, or 1 to 12)."},
+		{Name: optionBold, Type: typeBool, Default: "true", Description: "Display the title in bold."},
+		{Name: optionHeight, Type: typeInt, Default: "3", Description: "Height of the title, in lines."},
+	})
+
+	cacheOptions = []OptionSchema{
+		{Name: optionCacheTTL, Type: typeInt, Description: "Seconds the data are cached. Override the option of the service."},
+	}
+)
+
+// titleOption with its default value. The default is empty if the title depends on the data displayed.
+func titleOption(def string) []OptionSchema {
+	return []OptionSchema{{Name: optionTitle, Type: typeString, Default: def, Description: "Title of the widget."}}
+}
+
+// dateOptions for the period of the data displayed, with their default values.
+func dateOptions(start string, end string) []OptionSchema {
+	return []OptionSchema{
+		{Name: optionStartDate, Type: typeString, Default: start, Description: "First day of the period (for example 7_days_ago or 2019-08-01)."},
+		{Name: optionEndDate, Type: typeString, Default: end, Description: "Last day of the period (for example today or 2019-08-31)."},
+	}
+}
+
+// widgetSchema of a widget, with its name including the ID of its service (for example "ga.bar_sessions").
+func widgetSchema(name string, options ...[]OptionSchema) WidgetSchema {
+	return WidgetSchema{
+		Name:    strings.SplitN(name, ".", 2)[1],
+		Options: joinOptions(options...),
+	}
+}
+
+func joinOptions(options ...[]OptionSchema) []OptionSchema {
+	j := []OptionSchema{}
+	for _, o := range options {
+		j = append(j, o...)
+	}
+
+	return j
+}
+
+// withoutOptions the options with the names given, for the widgets which set these options themselves.
+func withoutOptions(options []OptionSchema, names ...string) []OptionSchema {
+	w := []OptionSchema{}
+	for _, o := range options {
+		if !contains(names, o.Name) {
+			w = append(w, o)
+		}
+	}
+
+	return w
+}
+
+// check the type of the value of an option.
+func (o OptionSchema) check(value string) error {
+	var err error
+	switch o.Type {
+	case typeInt:
+		_, err = strconv.ParseInt(value, 0, 0)
+	case typeBool:
+		_, err = strconv.ParseBool(value)
+	case typeSize:
+		_, err = MapSize(value)
+	case typeColor:
+		if _, ok := colorLookUp[value]; !ok {
+			err = errors.New("unknown color")
+		}
+	case typeAction:
+		if !contains([]string{actionOpen, actionCopy, actionCommand, actionNone}, value) {
+			err = errors.New("unknown action")
+		}
+	}
+
+	if err != nil {
+		return errors.Errorf("option %s should be of type %s, not %q", o.Name, o.Type, value)
+	}
+
+	return nil
+}
+
+// lookup the schema of an option.
+func lookupOption(schemas []OptionSchema, name string) (OptionSchema, bool) {
+	for _, o := range schemas {
+		if o.Name == name {
+			return o, true
+		}
+	}
+
+	return OptionSchema{}, false
+}
+
+// optionValue of the options, or its default value if it's not set.
+func optionValue(schemas []OptionSchema, options map[string]string, name string) string {
+	if v, ok := options[name]; ok {
+		return v
+	}
+
+	o, _ := lookupOption(schemas, name)
+	return o.Default
+}
+
+// intValue of an option of type int, or its default value if it's not set.
+func intValue(schemas []OptionSchema, options map[string]string, name string) (int64, error) {
+	v := optionValue(schemas, options, name)
+	i, err := strconv.ParseInt(v, 0, 0)
+	if err != nil {
+		return 0, errors.Errorf("option %s should be of type %s, not %q", name, typeInt, v)
+	}
+
+	return i, nil
+}
+
+// boolValue of an option of type bool, or its default value if it's not set.
+func boolValue(schemas []OptionSchema, options map[string]string, name string) (bool, error) {
+	v := optionValue(schemas, options, name)
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.Errorf("option %s should be of type %s, not %q", name, typeBool, v)
+	}
+
+	return b, nil
+}
+
+// schema of the widget, if the widget exists.
+func (w Widget) schema() (WidgetSchema, bool) {
+	def, err := lookupService(w.serviceType())
+	if err != nil {
+		return WidgetSchema{}, false
+	}
+
+	i := strings.Index(w.Name, ".")
+	if i == -1 {
+		return WidgetSchema{}, false
+	}
+
+	return def.widget(w.Name[i+1:])
+}
+
+// option of the widget, or its default value if it's not set.
+func (w Widget) option(name string) string {
+	s, _ := w.schema()
+	return optionValue(s.Options, w.Options, name)
+}
+
+// intOption of the widget, or its default value if it's not set.
+func (w Widget) intOption(name string) (int64, error) {
+	s, _ := w.schema()
+	return intValue(s.Options, w.Options, name)
+}
+
+// boolOption of the widget, or its default value if it's not set.
+func (w Widget) boolOption(name string) (bool, error) {
+	s, _ := w.schema()
+	return boolValue(s.Options, w.Options, name)
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"testing"
+)
+
+func Test_widgetSchemas(t *testing.T) {
+	types := map[string]OptionType{}
+	for _, o := range projectTitleOptions {
+		types[o.Name] = o.Type
+	}
+
+	for _, def := range ServiceDefinitions() {
+		widgets := map[string]bool{}
+		for _, w := range def.Widgets {
+			if widgets[w.Name] {
+				t.Errorf("Expected widget %s.%s declared once", def.ID, w.Name)
+			}
+			widgets[w.Name] = true
+
+			options := map[string]bool{}
+			for _, o := range w.Options {
+				if options[o.Name] {
+					t.Errorf("Expected option %s of widget %s.%s declared once", o.Name, def.ID, w.Name)
+				}
+				options[o.Name] = true
+
+				if o.Description == "" {
+					t.Errorf("Expected a description for option %s of widget %s.%s", o.Name, def.ID, w.Name)
+				}
+
+				if o.Default != "" {
+					if err := o.check(o.Default); err != nil {
+						t.Errorf("Expected valid default for widget %s.%s, actual %v", def.ID, w.Name, err)
+					}
+				}
+
+				// The themes and the validation of the configuration rely on the same type for the same option.
+				if ty, ok := types[o.Name]; ok && ty != o.Type {
+					t.Errorf("Expected type %v for option %s of widget %s.%s, actual %v", ty, o.Name, def.ID, w.Name, o.Type)
+				}
+				types[o.Name] = o.Type
+			}
+		}
+	}
+}
+
+func Test_intOption(t *testing.T) {
+	testCases := []struct {
+		name     string
+		widget   Widget
+		expected int64
+		wantErr  bool
+	}{
+		{
+			name:     "default value",
+			widget:   Widget{Name: "github.table_issues"},
+			expected: 5,
+		},
+		{
+			name:     "option set",
+			widget:   Widget{Name: "github.table_issues", Options: map[string]string{optionRowLimit: "10"}},
+			expected: 10,
+		},
+		{
+			name:    "wrong type",
+			widget:  Widget{Name: "github.table_issues", Options: map[string]string{optionRowLimit: "ten"}},
+			wantErr: true,
+		},
+		{
+			name:    "widget without the option",
+			widget:  Widget{Name: "lh.box_uptime"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.widget.intOption(optionRowLimit)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_option(t *testing.T) {
+	testCases := []struct {
+		name     string
+		widget   Widget
+		expected string
+	}{
+		{
+			name:     "default value",
+			widget:   Widget{Name: "rh.box_disk_io"},
+			expected: "kb",
+		},
+		{
+			name:     "default value of an instance",
+			widget:   Widget{Name: "rh:web1.box_disk_io"},
+			expected: "kb",
+		},
+		{
+			name:     "option set",
+			widget:   Widget{Name: "rh.box_disk_io", Options: map[string]string{optionUnit: "mb"}},
+			expected: "mb",
+		},
+		{
+			name:     "unknown widget",
+			widget:   Widget{Name: "rh.box_disk"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.widget.option(optionUnit)
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package platform
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// actionTimeout of the clipboard commands and of the commands run on the rows.
+var actionTimeout = 30 * time.Second
+
+// clipboards are the commands tried, in order, to copy a text to the clipboard.
+var clipboards = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// OpenURL in the browser of the environment variable $BROWSER, or in the default browser of the system.
+func OpenURL(url string) error {
+	if url == "" {
+		return errors.New("no URL to open")
+	}
+
+	browser := os.Getenv("BROWSER")
+	if browser == "" {
+		browser = "xdg-open"
+		if runtime.GOOS == "darwin" {
+			browser = "open"
+		}
+	}
+
+	// $BROWSER can be a list of browsers separated by colons: the first one is used.
+	browser = strings.Split(browser, ":")[0]
+	cmd := exec.Command(browser, url)
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "can't open %s with %s", url, browser)
+	}
+	// The browser can keep running after devdash.
+	go cmd.Wait()
+
+	return nil
+}
+
+// CopyToClipboard copy a text with the first clipboard command available.
+func CopyToClipboard(text string) error {
+	for _, c := range clipboards {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+
+		// The output isn't read: xclip keeps running in the background to own the clipboard, and would keep it open.
+		cmd := exec.CommandContext(ctx, c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := runAction(ctx, cmd); err != nil {
+			return errors.Wrapf(err, "can't copy with %s", c[0])
+		}
+		return nil
+	}
+
+	return errors.New("no clipboard command found (pbcopy, wl-copy, xclip or xsel)")
+}
+
+// RunCommand in a shell, with some additional environment variables (for example "DEVDASH_URL=https://...").
+// The output of the command is discarded.
+func RunCommand(command string, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	if err := runAction(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "command %s failed", command)
+	}
+
+	return nil
+}
+
+// runAction command without reading its output, and return an error if it fails or times out.
+func runAction(ctx context.Context, cmd *exec.Cmd) error {
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %s", actionTimeout)
+	}
+
+	return err
+}
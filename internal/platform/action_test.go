@@ -0,0 +1,85 @@
+package platform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_CopyToClipboard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Like xclip, the clipboard keeps running in the background after the copy.
+	copied := filepath.Join(dir, "copied")
+	clipboard := filepath.Join(dir, "clipboard")
+	script := "#!/bin/sh\ncat > " + copied + "\nsleep 5 &\n"
+	if err := ioutil.WriteFile(clipboard, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(c [][]string) { clipboards = c }(clipboards)
+	clipboards = [][]string{{filepath.Join(dir, "missing")}, {clipboard}}
+
+	start := time.Now()
+	if err := CopyToClipboard("https://thevaluable.dev"); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Expected the copy to return right away, actual %v", d)
+	}
+
+	content, _ := ioutil.ReadFile(copied)
+	if string(content) != "https://thevaluable.dev" {
+		t.Errorf("Expected %v, actual %v", "https://thevaluable.dev", string(content))
+	}
+}
+
+func Test_RunCommand(t *testing.T) {
+	defer func(d time.Duration) { actionTimeout = d }(actionTimeout)
+	actionTimeout = 500 * time.Millisecond
+
+	testCases := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{
+			name:    "command with output",
+			command: `echo "$DEVDASH_URL"; echo error >&2`,
+		},
+		{
+			name:    "command leaving a process in the background",
+			command: "sleep 5 &",
+		},
+		{
+			name:    "command failing",
+			command: "exit 1",
+			wantErr: true,
+		},
+		{
+			name:    "command timing out",
+			command: "sleep 5",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Now()
+			err := RunCommand(tc.command, []string{"DEVDASH_URL=https://thevaluable.dev"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if d := time.Since(start); d > 2*time.Second {
+				t.Errorf("Expected the command to return before 2s, actual %v", d)
+			}
+		})
+	}
+}
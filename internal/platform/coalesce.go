@@ -0,0 +1,73 @@
+package platform
+
+// Coalesce the identical calls to the platforms during a refresh of the dashboard.
+// For example, multiple widgets running the same command on the same host only run it once, and share the result.
+
+import (
+	"context"
+	"sync"
+)
+
+type coalescerKey struct{}
+
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+	// canceled is true if the call was interrupted by the context of the caller running it.
+	canceled bool
+}
+
+// WithCoalescer return a context sharing the results of the identical calls made with it.
+// The results are kept as long as the context is used: a new one should be created for each refresh.
+func WithCoalescer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, coalescerKey{}, &coalescer{calls: map[string]*call{}})
+}
+
+// coalesce the calls with the same key: the function fn is only run by the first caller.
+// The other callers wait for the result. If the context doesn't have any coalescer, fn is simply run.
+func coalesce(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	c, ok := ctx.Value(coalescerKey{}).(*coalescer)
+	if !ok {
+		return fn()
+	}
+
+	c.mu.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-cl.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// The caller running the call gave up (timeout): the result can't be shared.
+		if cl.canceled {
+			return fn()
+		}
+
+		return cl.val, cl.err
+	}
+
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	cl.val, cl.err = fn()
+	cl.canceled = cl.err != nil && ctx.Err() != nil
+	if cl.err != nil {
+		// Don't keep the errors: the next callers try again.
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+	}
+	close(cl.done)
+
+	return cl.val, cl.err
+}
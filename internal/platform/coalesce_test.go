@@ -0,0 +1,75 @@
+package platform
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_coalesce(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected int32
+		ctx      context.Context
+	}{
+		{
+			name:     "without coalescer",
+			expected: 3,
+			ctx:      context.Background(),
+		},
+		{
+			name:     "with coalescer",
+			expected: 1,
+			ctx:      WithCoalescer(context.Background()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var runs int32
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					val, err := coalesce(tc.ctx, "host|localhost@localhost|uptime", func() (interface{}, error) {
+						atomic.AddInt32(&runs, 1)
+						time.Sleep(10 * time.Millisecond)
+						return "42", nil
+					})
+					if err != nil || val.(string) != "42" {
+						t.Errorf("Expected 42, actual %v (%v)", val, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if runs != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, runs)
+			}
+		})
+	}
+}
+
+func Test_coalesceCanceled(t *testing.T) {
+	ctx := WithCoalescer(context.Background())
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+
+	started := make(chan struct{})
+	go coalesce(timeout, "key", func() (interface{}, error) {
+		close(started)
+		<-timeout.Done()
+		return nil, timeout.Err()
+	})
+	<-started
+
+	val, err := coalesce(ctx, "key", func() (interface{}, error) {
+		return "fresh", nil
+	})
+	if err != nil || val.(string) != "fresh" {
+		t.Errorf("Expected fresh, actual %v (%v)", val, err)
+	}
+}
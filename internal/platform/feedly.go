@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -33,9 +34,13 @@ func NewFeedly(address string) *Feedly {
 	}
 }
 
-func (f *Feedly) Subscribers() (string, error) {
-	url := f.createAPIURL()
-	resp, err := f.Client.Get(url)
+func (f *Feedly) Subscribers(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.createAPIURL(), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "can't create request for feedly API")
+	}
+
+	resp, err := f.Client.Do(req)
 	if err != nil {
 		return "", errors.Wrap(err, "error while fetching feedly API")
 	}
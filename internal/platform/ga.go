@@ -2,6 +2,7 @@ package platform
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -132,8 +133,37 @@ func NewAnalyticsClient(keyfile string) (*Analytics, error) {
 
 }
 
+// batchGet the reports of a request. The identical requests made during the same refresh are only sent once.
+func (c *Analytics) batchGet(ctx context.Context, req *ga.GetReportsRequest) (*ga.GetReportsResponse, error) {
+	key, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't encode google analytics request")
+	}
+
+	resp, err := coalesce(ctx, fmt.Sprintf("ga|%p|%s", c, key), func() (interface{}, error) {
+		return c.service.Reports.BatchGet(req).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*ga.GetReportsResponse), nil
+}
+
+// realtimeGet a real time metric. The identical requests made during the same refresh are only sent once.
+func (c *Analytics) realtimeGet(ctx context.Context, viewID string, metric string) (*gav3.RealtimeData, error) {
+	resp, err := coalesce(ctx, fmt.Sprintf("ga_realtime|%p|%s|%s", c, viewID, metric), func() (interface{}, error) {
+		return c.realtimeService.Get(gaPrefix+viewID, metric).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*gav3.RealtimeData), nil
+}
+
 // SimpleMetric get a value depending on Google Analytics metrics.
-func (c *Analytics) SimpleMetric(val AnalyticValues) (string, error) {
+func (c *Analytics) SimpleMetric(ctx context.Context, val AnalyticValues) (string, error) {
 	req := &ga.GetReportsRequest{
 		ReportRequests: []*ga.ReportRequest{
 			{
@@ -147,7 +177,7 @@ func (c *Analytics) SimpleMetric(val AnalyticValues) (string, error) {
 		},
 	}
 
-	resp, err := c.service.Reports.BatchGet(req).Do()
+	resp, err := c.batchGet(ctx, req)
 	if err != nil {
 		return "", errors.Wrapf(
 			err,
@@ -165,7 +195,7 @@ func (c *Analytics) SimpleMetric(val AnalyticValues) (string, error) {
 }
 
 // BarMetric provides a qualitive dimension linked to a quantitative value, for example a date (dimension) with an int.
-func (c *Analytics) BarMetric(val AnalyticValues) ([]string, []int, error) {
+func (c *Analytics) BarMetric(ctx context.Context, val AnalyticValues) ([]string, []int, error) {
 	// Add the time dimension to the first two indexes of the slice ga.Dimensions(index 0 and 1)
 	tm := mapTimePeriod(val.TimePeriod)
 	dim := []*ga.Dimension{}
@@ -220,7 +250,7 @@ func (c *Analytics) BarMetric(val AnalyticValues) ([]string, []int, error) {
 		},
 	}
 
-	resp, err := c.service.Reports.BatchGet(req).Do()
+	resp, err := c.batchGet(ctx, req)
 
 	if err != nil {
 		return nil, nil, errors.Wrapf(
@@ -250,10 +280,10 @@ func (c *Analytics) BarMetric(val AnalyticValues) ([]string, []int, error) {
 }
 
 // RealTimeUsers return the number of visitor currently on the website.
-func (c *Analytics) RealTimeUsers(viewID string) (string, error) {
+func (c *Analytics) RealTimeUsers(ctx context.Context, viewID string) (string, error) {
 	metric := "rt:activeUsers"
 
-	resp, err := c.realtimeService.Get(gaPrefix+viewID, metric).Do()
+	resp, err := c.realtimeGet(ctx, viewID, metric)
 	if err != nil {
 		return "", err
 	}
@@ -264,6 +294,7 @@ func (c *Analytics) RealTimeUsers(viewID string) (string, error) {
 // Table display dimensions and values.
 // The headers on the first row are qualitative dimensions, the values can be qualitative or quantitative.
 func (c *Analytics) Table(
+	ctx context.Context,
 	an AnalyticValues,
 	firstHeader string,
 ) (headers []string, dim []string, u [][]string, err error) {
@@ -316,7 +347,7 @@ func (c *Analytics) Table(
 		}
 	}
 
-	resp, err := c.service.Reports.BatchGet(req).Do()
+	resp, err := c.batchGet(ctx, req)
 	if err != nil {
 		return nil, nil, nil, errors.Wrapf(
 			err,
@@ -336,7 +367,7 @@ func (c *Analytics) Table(
 }
 
 // StackedBar returns one dimension set linked with multiple values.
-func (c *Analytics) StackedBar(an AnalyticValues) (dim []string, values map[string][]int, err error) {
+func (c *Analytics) StackedBar(ctx context.Context, an AnalyticValues) (dim []string, values map[string][]int, err error) {
 	d := mapDimensions(an.Dimensions)
 	tm := mapTimePeriod(an.TimePeriod)
 
@@ -365,7 +396,7 @@ func (c *Analytics) StackedBar(an AnalyticValues) (dim []string, values map[stri
 		},
 	}
 
-	resp, err := c.service.Reports.BatchGet(req).Do()
+	resp, err := c.batchGet(ctx, req)
 
 	if err != nil {
 		return nil, nil, errors.Wrapf(
@@ -2,6 +2,7 @@ package platform
 
 import (
 	"bytes"
+	"context"
 	"os/exec"
 	"strings"
 
@@ -20,8 +21,9 @@ func NewGit(path string) *Git {
 	}
 }
 
-func (g *Git) Branches() ([][]string, error) {
-	cmd := exec.Command(
+func (g *Git) Branches(ctx context.Context) ([][]string, error) {
+	cmd := exec.CommandContext(
+		ctx,
 		git,
 		"for-each-ref",
 		"--sort=committerdate",
@@ -53,3 +55,23 @@ func formatBranches(data string) [][]string {
 
 	return result
 }
+
+// CheckGit is installed.
+func CheckGit() error {
+	if _, err := exec.LookPath(git); err != nil {
+		return errors.Wrapf(err, "can't find %s", git)
+	}
+
+	return nil
+}
+
+// CheckRepository of the path is a git repository.
+func (g *Git) CheckRepository(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, git, "rev-parse", "--git-dir")
+	cmd.Dir = g.Path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "%s is not a git repository: %s", g.Path, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
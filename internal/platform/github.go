@@ -6,8 +6,11 @@ package platform
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -56,8 +59,8 @@ func NewGithubClient(token string, owner string, repoName string) (*Github, erro
 }
 
 // TotalStars of a repository.
-func (g *Github) TotalStars(repository string) (int, error) {
-	r, err := g.fetchRepo(repository)
+func (g *Github) TotalStars(ctx context.Context, repository string) (int, error) {
+	r, err := g.fetchRepo(ctx, repository)
 	if err != nil {
 		return 0, err
 	}
@@ -66,8 +69,8 @@ func (g *Github) TotalStars(repository string) (int, error) {
 }
 
 // TotalWatchers of a repository overtime.
-func (g *Github) TotalWatchers(repository string) (int, error) {
-	r, err := g.fetchRepo(repository)
+func (g *Github) TotalWatchers(ctx context.Context, repository string) (int, error) {
+	r, err := g.fetchRepo(ctx, repository)
 	if err != nil {
 		return 0, err
 	}
@@ -76,8 +79,8 @@ func (g *Github) TotalWatchers(repository string) (int, error) {
 }
 
 // TotalOpenIssues of a repository overtime.
-func (g *Github) TotalOpenIssues(repository string) (int, error) {
-	r, err := g.fetchRepo(repository)
+func (g *Github) TotalOpenIssues(ctx context.Context, repository string) (int, error) {
+	r, err := g.fetchRepo(ctx, repository)
 	if err != nil {
 		return 0, err
 	}
@@ -86,10 +89,10 @@ func (g *Github) TotalOpenIssues(repository string) (int, error) {
 }
 
 // ListBranches of a repository.
-func (g *Github) ListBranches(repository string, limit int) ([][]string, error) {
+func (g *Github) ListBranches(ctx context.Context, repository string, limit int) ([][]string, error) {
 	headers := []string{"name"}
 
-	bs, err := g.fetchBranches(repository, limit)
+	bs, err := g.fetchBranches(ctx, repository, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -115,10 +118,10 @@ func (g *Github) ListBranches(repository string, limit int) ([][]string, error)
 }
 
 // ListRepo of a Github account.
-func (g *Github) ListRepo(limit int, order string, metrics []string) ([][]string, error) {
+func (g *Github) ListRepo(ctx context.Context, limit int, order string, metrics []string) ([][]string, error) {
 	headers := []string{"name"}
 
-	rs, err := g.fetchAllRepo(order)
+	rs, err := g.fetchAllRepo(ctx, order)
 	if err != nil {
 		return nil, err
 	}
@@ -174,19 +177,20 @@ func (g *Github) ListRepo(limit int, order string, metrics []string) ([][]string
 	return repos, nil
 }
 
-// ListIssues of a repository.
-func (g *Github) ListIssues(repository string, limit int) ([][]string, error) {
+// ListIssues of a repository, with the URL of each issue.
+func (g *Github) ListIssues(ctx context.Context, repository string, limit int) ([][]string, []string, error) {
 	headers := []string{"name", "state"}
 
-	is, err := g.fetchIssues(repository, limit)
+	is, err := g.fetchIssues(ctx, repository, limit)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if limit > len(is) {
 		limit = len(is)
 	}
 
+	urls := make([]string, limit)
 	issues := make([][]string, limit+1)
 	issues[0] = headers
 	for k, v := range is {
@@ -203,29 +207,33 @@ func (g *Github) ListIssues(repository string, limit int) ([][]string, error) {
 		if k < limit {
 			issues[k+1] = append(issues[k+1], n)
 			issues[k+1] = append(issues[k+1], state)
+			urls[k] = v.GetHTMLURL()
 		}
 	}
 
-	return issues, nil
+	return issues, urls, nil
 }
 
-// ListPullRequests of a repository.
-func (g *Github) ListPullRequests(repository string, limit int) ([][]string, error) {
-	is, err := g.fetchPullRequests(repository, limit)
+// ListPullRequests of a repository, with the URL of each pull request.
+func (g *Github) ListPullRequests(ctx context.Context, repository string, limit int) ([][]string, []string, error) {
+	is, err := g.fetchPullRequests(ctx, repository, limit)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	lpr := formatListPullRequests(is, limit)
+	lpr, urls := formatListPullRequests(is, limit)
 
-	return lpr, nil
+	return lpr, urls, nil
 }
 
-func formatListPullRequests(is []*github.PullRequest, limit int) [][]string {
+// formatListPullRequests in a table, and return the URLs of the pull requests with the same indexes as the rows without the header.
+func formatListPullRequests(is []*github.PullRequest, limit int) ([][]string, []string) {
 	if limit > len(is) {
 		limit = len(is)
 	}
 
+	urls := make([]string, limit)
+
 	headers := []string{"title", "state", "created at", "merged", "commits"}
 
 	defaultHeader := "unknown"
@@ -266,15 +274,16 @@ func formatListPullRequests(is []*github.PullRequest, limit int) [][]string {
 			prs[k+1] = append(prs[k+1], createdAt)
 			prs[k+1] = append(prs[k+1], merged)
 			prs[k+1] = append(prs[k+1], commits)
+			urls[k] = v.GetHTMLURL()
 		}
 	}
 
-	return prs
+	return prs, urls
 }
 
 // Views on a github repository the last 7 days.
-func (g *Github) Views(repository string, days int) ([]string, []int, error) {
-	tv, err := g.fetchViews(repository)
+func (g *Github) Views(ctx context.Context, repository string, days int) ([]string, []int, error) {
+	tv, err := g.fetchViews(ctx, repository)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -294,13 +303,14 @@ func (g *Github) Views(repository string, days int) ([]string, []int, error) {
 
 // CountCommits of a repository overtime.
 func (g *Github) CountCommits(
+	ctx context.Context,
 	repository string,
 	scope string,
 	startWeek int64,
 	endWeek int64,
 	startDate time.Time,
 ) ([]string, []int, error) {
-	c, err := g.fetchCommitCount(repository)
+	c, err := g.fetchCommitCount(ctx, repository)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -363,8 +373,8 @@ func formatCountCommits(
 
 // CountStars of a repository overtime.
 // Only on a daily basis for now.
-func (g *Github) CountStars(repository string, startDate, endDate time.Time) (dim []string, val []int, err error) {
-	se, err := g.fetchStars(repository)
+func (g *Github) CountStars(ctx context.Context, repository string, startDate, endDate time.Time) (dim []string, val []int, err error) {
+	se, err := g.fetchStars(ctx, repository)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -420,7 +430,7 @@ func aggregateStarResults(stargazers []*github.Stargazer) (dim []time.Time, val
 
 // fetchStars from the Github API. Every stars are fetched.
 // Unfortunatelly, perPage is limited to 100, so we need multiple requests.
-func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error) {
+func (g *Github) fetchStars(ctx context.Context, repository string) (s []*github.Stargazer, err error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -430,7 +440,7 @@ func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error
 		return nil, errors.New("you need to specify a repository in the github service or in the widget")
 	}
 
-	r, err := g.fetchRepo(repository)
+	r, err := g.fetchRepo(ctx, repository)
 	if err != nil {
 		return nil, err
 	}
@@ -441,7 +451,7 @@ func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error
 	}
 
 	var lock sync.Mutex
-	var eg errgroup.Group
+	eg, ctx := errgroup.WithContext(ctx)
 	sem := make(chan bool, 4)
 
 	// TODO See if it can be improved
@@ -450,7 +460,7 @@ func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error
 		page := i
 		eg.Go(func() error {
 			defer func() { <-sem }()
-			e, _, err := g.client.Activity.ListStargazers(context.Background(), g.owner, repo, &github.ListOptions{
+			e, _, err := g.client.Activity.ListStargazers(ctx, g.owner, repo, &github.ListOptions{
 				Page:    page,
 				PerPage: githubMaxPerPage,
 			})
@@ -472,7 +482,7 @@ func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error
 	return s, nil
 }
 
-func (g *Github) fetchRepo(repository string) (*github.Repository, error) {
+func (g *Github) fetchRepo(ctx context.Context, repository string) (*github.Repository, error) {
 	// TODO add a TTL
 	if g.repo != nil {
 		return g.repo, nil
@@ -487,17 +497,21 @@ func (g *Github) fetchRepo(repository string) (*github.Repository, error) {
 		return nil, errors.New("you need to specify a repository in the github service or in the widget")
 	}
 
-	r, _, err := g.client.Repositories.Get(context.Background(), g.owner, repo)
+	// The boxes of the same repository (stars, watchers, issues) only fetch it once per refresh.
+	r, err := coalesce(ctx, fmt.Sprintf("github|%p|%s|%s", g, g.owner, repo), func() (interface{}, error) {
+		r, _, err := g.client.Repositories.Get(ctx, g.owner, repo)
+		return r, err
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find repo %s of owner %s", repo, g.owner)
 	}
 
-	return r, nil
+	return r.(*github.Repository), nil
 
 }
 
 // TODO possibility to filter by ALL or OWNER
-func (g *Github) fetchCommitCount(repository string) (*github.RepositoryParticipation, error) {
+func (g *Github) fetchCommitCount(ctx context.Context, repository string) (*github.RepositoryParticipation, error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -507,7 +521,7 @@ func (g *Github) fetchCommitCount(repository string) (*github.RepositoryParticip
 		return nil, errors.New("you need to specify a repository in the github service or in the widget")
 	}
 
-	p, _, err := g.client.Repositories.ListParticipation(context.Background(), g.owner, repo)
+	p, _, err := g.client.Repositories.ListParticipation(ctx, g.owner, repo)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find repo %s of owner %s", repo, g.owner)
 	}
@@ -515,7 +529,7 @@ func (g *Github) fetchCommitCount(repository string) (*github.RepositoryParticip
 	return p, nil
 }
 
-func (g *Github) fetchViews(repository string) (*github.TrafficViews, error) {
+func (g *Github) fetchViews(ctx context.Context, repository string) (*github.TrafficViews, error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -525,7 +539,7 @@ func (g *Github) fetchViews(repository string) (*github.TrafficViews, error) {
 		return nil, errors.New("you need to specify a repository in the github service or in the widget")
 	}
 
-	t, _, err := g.client.Repositories.ListTrafficViews(context.Background(), g.owner, repo, nil)
+	t, _, err := g.client.Repositories.ListTrafficViews(ctx, g.owner, repo, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find repo %s of owner %s", repo, g.owner)
 	}
@@ -533,7 +547,7 @@ func (g *Github) fetchViews(repository string) (*github.TrafficViews, error) {
 	return t, nil
 }
 
-func (g *Github) fetchBranches(repository string, limit int) ([]*github.Branch, error) {
+func (g *Github) fetchBranches(ctx context.Context, repository string, limit int) ([]*github.Branch, error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -544,7 +558,7 @@ func (g *Github) fetchBranches(repository string, limit int) ([]*github.Branch,
 	}
 
 	opt := github.ListOptions{PerPage: limit}
-	bs, _, err := g.client.Repositories.ListBranches(context.Background(), g.owner, repo, &opt)
+	bs, _, err := g.client.Repositories.ListBranches(ctx, g.owner, repo, &opt)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find branches of owner %s for repo %s", g.owner, repo)
 	}
@@ -553,7 +567,7 @@ func (g *Github) fetchBranches(repository string, limit int) ([]*github.Branch,
 }
 
 // Possibility to add options to filter quite a lot
-func (g *Github) fetchIssues(repository string, limit int) ([]*github.Issue, error) {
+func (g *Github) fetchIssues(ctx context.Context, repository string, limit int) ([]*github.Issue, error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -567,7 +581,7 @@ func (g *Github) fetchIssues(repository string, limit int) ([]*github.Issue, err
 		State:       "all",
 		ListOptions: github.ListOptions{PerPage: limit},
 	}
-	is, _, err := g.client.Issues.ListByRepo(context.Background(), g.owner, repo, &opt)
+	is, _, err := g.client.Issues.ListByRepo(ctx, g.owner, repo, &opt)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find branches of owner %s for repo %s", g.owner, repo)
 	}
@@ -576,8 +590,7 @@ func (g *Github) fetchIssues(repository string, limit int) ([]*github.Issue, err
 }
 
 // TODO add sorting
-func (g *Github) fetchPullRequests(repository string, limit int) ([]*github.PullRequest, error) {
-	ctx := context.Background()
+func (g *Github) fetchPullRequests(ctx context.Context, repository string, limit int) ([]*github.PullRequest, error) {
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -595,9 +608,7 @@ func (g *Github) fetchPullRequests(repository string, limit int) ([]*github.Pull
 }
 
 // TODO possibility to add filters / ordering
-func (g *Github) fetchAllRepo(order string) ([]*github.Repository, error) {
-	ctx := context.Background()
-
+func (g *Github) fetchAllRepo(ctx context.Context, order string) ([]*github.Repository, error) {
 	r, _, err := g.client.Repositories.List(ctx, g.owner, &github.RepositoryListOptions{Sort: order})
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't find all repo of owner %s", g.owner)
@@ -638,3 +649,33 @@ func fillMissingDays(dates []time.Time, values []int) ([]time.Time, []int) {
 
 	return d, val
 }
+
+// TokenScopes of the token of the client. The scopes are empty for the fine-grained tokens.
+// Return an error if the token is not valid anymore.
+func (g *Github) TokenScopes(ctx context.Context) ([]string, error) {
+	_, resp, err := g.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{}
+	for _, s := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes, nil
+}
+
+// IsUnauthorized is true if Github refused the token of the client.
+func IsUnauthorized(err error) bool {
+	e, ok := errors.Cause(err).(*github.ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode == http.StatusUnauthorized
+}
+
+// CheckRepository can be fetched with the token of the client.
+func (g *Github) CheckRepository(ctx context.Context, repository string) error {
+	_, err := g.fetchRepo(ctx, repository)
+	return err
+}
@@ -135,10 +135,11 @@ func Test_formatCommitCount(t *testing.T) {
 
 func Test_FomatListPullRequest(t *testing.T) {
 	testCases := []struct {
-		name        string
-		expected    [][]string
-		fixtureFile string
-		limit       int
+		name         string
+		expected     [][]string
+		expectedURLs []string
+		fixtureFile  string
+		limit        int
 	}{
 		{
 			name: "happy case",
@@ -158,8 +159,9 @@ func Test_FomatListPullRequest(t *testing.T) {
 					"unknown",
 				},
 			},
-			fixtureFile: "./testdata/fixtures/github_list_pull_request.json",
-			limit:       1000000,
+			expectedURLs: []string{"https://github.com/Phantas0s/devdash/pull/1"},
+			fixtureFile:  "./testdata/fixtures/github_list_pull_request.json",
+			limit:        1000000,
 		},
 	}
 
@@ -172,11 +174,15 @@ func Test_FomatListPullRequest(t *testing.T) {
 				t.Error(err)
 			}
 
-			actual := formatListPullRequests(gpr, tc.limit)
+			actual, urls := formatListPullRequests(gpr, tc.limit)
 
 			if !reflect.DeepEqual(tc.expected, actual) {
 				t.Errorf("Expected %v, actual %v", tc.expected, actual)
 			}
+
+			if !reflect.DeepEqual(tc.expectedURLs, urls) {
+				t.Errorf("Expected %v, actual %v", tc.expectedURLs, urls)
+			}
 		})
 	}
 }
@@ -64,6 +64,7 @@ func NewSearchConsoleClient(keyfile string) (*SearchConsole, error) {
 
 // Table of Google Search Console with a dimension and its values.
 func (w *SearchConsole) Table(
+	ctx context.Context,
 	startDate string,
 	endDate string,
 	limit int64,
@@ -83,7 +84,7 @@ func (w *SearchConsole) Table(
 		RowLimit: limit,
 	}
 
-	resp, err := w.service.Searchanalytics.Query(address, req).Do()
+	resp, err := w.service.Searchanalytics.Query(address, req).Context(ctx).Do()
 	if err != nil {
 		return nil, err
 	}
@@ -138,3 +139,9 @@ func formatSearchTable(rows []*sc.ApiDataRow) []SearchConsoleResponse {
 
 	return results
 }
+
+// CheckSite can be read with the keyfile of the client.
+func (s *SearchConsole) CheckSite(ctx context.Context, address string) error {
+	_, err := s.service.Sites.Get(address).Context(ctx).Do()
+	return err
+}
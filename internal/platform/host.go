@@ -7,10 +7,13 @@ package platform
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/devdash/gokit"
@@ -23,38 +26,116 @@ const (
 )
 
 type Host struct {
+	mu        sync.Mutex
 	sshClient *ssh.Client
+	// agentConn to ssh-agent, opened with the SSH client.
+	agentConn net.Conn
 	localhost bool
+	username  string
+	addr      string
 }
 
 // syntactic sugar
 type runnerFunc func(cmd string) (string, error)
 
-func NewHost(username, addr string) (*Host, error) {
-	if username == "localhost" && addr == "localhost" {
-		return &Host{
-			sshClient: nil,
-			localhost: true,
-		}, nil
+// NewHost to run commands on. The connection to a remote host is only opened when a command is run.
+func NewHost(username, addr string) *Host {
+	return &Host{
+		localhost: username == "localhost" && addr == "localhost",
+		username:  username,
+		addr:      addr,
+	}
+}
+
+// Connect to the remote host, if it's not connected yet.
+func (s *Host) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connect()
+}
+
+func (s *Host) connect() error {
+	if s.localhost || s.sshClient != nil {
+		return nil
 	}
 
-	sshClient, err := sshAgentAuth(username, addr)
+	sshClient, agentConn, err := sshAgentAuth(s.username, s.addr)
 	if err != nil {
+		return err
+	}
+	s.sshClient, s.agentConn = sshClient, agentConn
+
+	return nil
+}
+
+// Close the SSH connection to the remote host, and the connection to ssh-agent.
+func (s *Host) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.disconnect()
+}
+
+func (s *Host) disconnect() error {
+	if s.sshClient == nil {
+		return nil
+	}
+
+	err := s.sshClient.Close()
+	s.agentConn.Close()
+	s.sshClient, s.agentConn = nil, nil
+
+	return err
+}
+
+// session with the remote host. Connect if the connection isn't opened yet, or if it was lost or closed.
+func (s *Host) session() (*ssh.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sshClient != nil {
+		session, err := s.sshClient.NewSession()
+		if err == nil {
+			return session, nil
+		}
+		s.disconnect()
+	}
+
+	if err := s.connect(); err != nil {
 		return nil, err
 	}
-	return &Host{
-		sshClient: sshClient,
-		localhost: false,
-	}, nil
+
+	return s.sshClient.NewSession()
+}
+
+// Runner return a function to run commands on remote server via SSH or on localhost.
+// The commands are interrupted when the context is done.
+func (s *Host) Runner(ctx context.Context) runnerFunc {
+	return func(command string) (string, error) {
+		return s.run(ctx, command)
+	}
+}
+
+// run a command on the host. The identical commands run at the same time, or during the same refresh, are only run once.
+func (s *Host) run(ctx context.Context, command string) (string, error) {
+	out, err := coalesce(ctx, "host|"+s.username+"@"+s.addr+"|"+command, func() (interface{}, error) {
+		return s.exec(ctx, command)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return out.(string), nil
 }
 
-// Run a command on remote server via SSH or on localhost
-func (s *Host) Runner(command string) (string, error) {
+// Execute a command on remote server via SSH or on localhost
+func (s *Host) exec(ctx context.Context, command string) (string, error) {
 	if s.localhost {
-		return runLocalhost(command)
+		return runLocalhost(ctx, command)
 	}
 
-	session, err := s.sshClient.NewSession()
+	session, err := s.session()
 	if err != nil {
 		return "", errors.Wrapf(err, "can't create session with SSH client for command %s", command)
 	}
@@ -62,17 +143,31 @@ func (s *Host) Runner(command string) (string, error) {
 
 	var buf bytes.Buffer
 	session.Stdout = &buf
-	err = session.Run(command)
-	if err != nil {
-		return "", errors.Wrapf(err, "can't run command %s on remote server", command)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	select {
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		return "", errors.Wrapf(ctx.Err(), "command %s interrupted on remote server", command)
+	case err = <-done:
+		if err != nil {
+			return "", errors.Wrapf(err, "can't run command %s on remote server", command)
+		}
 	}
 
 	return string(buf.Bytes()), nil
 }
 
-func runLocalhost(command string) (string, error) {
-	out, errs, err := gokit.ExecCmd(command)
+func runLocalhost(ctx context.Context, command string) (string, error) {
+	out, errs, err := gokit.ExecCmdContext(ctx, command)
 	if err != nil {
+		if ctx.Err() != nil {
+			return "", errors.Wrapf(ctx.Err(), "command %s interrupted", command)
+		}
 		return "", err
 	}
 
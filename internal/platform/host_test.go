@@ -789,3 +789,24 @@ func Test_HostBar(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewHost(t *testing.T) {
+	testCases := []struct {
+		name      string
+		username  string
+		addr      string
+		localhost bool
+	}{
+		{name: "localhost", username: "localhost", addr: "localhost", localhost: true},
+		{name: "remote host not connected", username: "root", addr: "192.0.2.1:22"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHost(tc.username, tc.addr)
+			if h.localhost != tc.localhost || h.sshClient != nil {
+				t.Errorf("Expected %v and no connection, actual %v and %v", tc.localhost, h.localhost, h.sshClient)
+			}
+		})
+	}
+}
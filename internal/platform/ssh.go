@@ -3,21 +3,27 @@ package platform
 import (
 	"net"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 )
 
-func sshAgentAuth(username, addr string) (*ssh.Client, error) {
+// sshDialTimeout is the maximum amount of time to establish the SSH connection.
+const sshDialTimeout = 10 * time.Second
+
+// sshAgentAuth connect to a host with the keys of ssh-agent. Return the SSH client and the connection to ssh-agent,
+// which need to be closed together.
+func sshAgentAuth(username, addr string) (*ssh.Client, net.Conn, error) {
 	s := os.Getenv(sshAgentEnv)
 	if s == "" {
-		return nil, errors.Errorf("%s environment varible empty", sshAgentEnv)
+		return nil, nil, errors.Errorf("%s environment varible empty", sshAgentEnv)
 	}
 
 	agentConn, err := net.Dial("unix", s)
 	if err != nil {
-		return nil, errors.Wrapf(err, "Can't connect via ssh-agent")
+		return nil, nil, errors.Wrapf(err, "Can't connect via ssh-agent")
 	}
 
 	auth := ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)
@@ -27,7 +33,39 @@ func sshAgentAuth(username, addr string) (*ssh.Client, error) {
 		HostKeyCallback: func(string, net.Addr, ssh.PublicKey) error {
 			return nil
 		},
+		Timeout: sshDialTimeout,
+	}
+
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		agentConn.Close()
+		return nil, nil, err
+	}
+
+	return client, agentConn, nil
+}
+
+// CheckSSHAgent is running and has keys, to connect to the remote hosts.
+// Return the number of keys of the agent.
+func CheckSSHAgent() (int, error) {
+	s := os.Getenv(sshAgentEnv)
+	if s == "" {
+		return 0, errors.Errorf("%s environment varible empty", sshAgentEnv)
+	}
+
+	agentConn, err := net.Dial("unix", s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Can't connect via ssh-agent")
+	}
+	defer agentConn.Close()
+
+	keys, err := agent.NewClient(agentConn).List()
+	if err != nil {
+		return 0, errors.Wrap(err, "can't list the keys of ssh-agent")
+	}
+	if len(keys) == 0 {
+		return 0, errors.New("ssh-agent has no key")
 	}
 
-	return ssh.Dial("tcp", addr, config)
+	return len(keys), nil
 }
@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_sshAgentAuthFailing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	agent, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+
+	// The host close the connections right away: the SSH handshake fails.
+	host, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	go func() {
+		for {
+			c, err := host.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	defer os.Setenv(sshAgentEnv, os.Getenv(sshAgentEnv))
+	os.Setenv(sshAgentEnv, agent.Addr().String())
+
+	h := NewHost("root", host.Addr().String())
+	if err := h.Connect(); err == nil {
+		t.Fatal("Expected the connection to fail")
+	}
+
+	conn, err := agent.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil || isTimeout(err) {
+		t.Errorf("Expected the connection to ssh-agent to be closed, actual %v", err)
+	}
+}
+
+func isTimeout(err error) bool {
+	e, ok := err.(net.Error)
+	return ok && e.Timeout()
+}
@@ -0,0 +1,167 @@
+package platform
+
+// The terminal is initialized and drawn like termui does, except for its events: termui drops the button of the
+// events of the mouse, so the events of termbox are converted here.
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Phantas0s/termui"
+	"github.com/nsf/termbox-go"
+)
+
+// terminalLock for the calls to termbox which draw on the terminal, or sync with it.
+var terminalLock sync.Mutex
+
+// mouseButtons of termbox, with the names of the buttons of the mouse events.
+var mouseButtons = map[termbox.Key]string{
+	termbox.MouseLeft:      mouseLeft,
+	termbox.MouseMiddle:    "MouseMiddle",
+	termbox.MouseRight:     "MouseRight",
+	termbox.MouseRelease:   "MouseRelease",
+	termbox.MouseWheelUp:   mouseWheelUp,
+	termbox.MouseWheelDown: mouseWheelDown,
+}
+
+// initTerminal like termui.Init, and send the events of termbox to the event stream of termui.
+func initTerminal() error {
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+
+	termui.Body = termui.NewGrid()
+	termui.Body.X = 0
+	termui.Body.Y = 0
+	termui.Body.BgColor = termui.ThemeAttr("bg")
+	termui.Body.Width = termWidth()
+
+	events := make(chan termui.Event)
+	go func() {
+		for {
+			events <- termboxEvent(termbox.PollEvent())
+		}
+	}()
+
+	es := termui.DefaultEvtStream
+	es.Init()
+	es.Merge("termbox", events)
+	es.Merge("timer", termui.NewTimerCh(time.Second))
+	es.Handle("/", termui.DefaultHandler)
+	es.Handle("/sys/wnd/resize", func(e termui.Event) {
+		termui.Body.Width = e.Data.(termui.EvtWnd).Width
+	})
+
+	termui.DefaultWgtMgr = termui.NewWgtMgr()
+	es.Hook(termui.DefaultWgtMgr.WgtHandlersHook())
+
+	return nil
+}
+
+// termboxEvent converted to an event of termui. The events of the mouse have the button pressed.
+func termboxEvent(e termbox.Event) termui.Event {
+	ne := termui.Event{From: "/sys", Time: time.Now().Unix()}
+
+	switch e.Type {
+	case termbox.EventKey:
+		kbd := keyboardEvent(e)
+		ne.Type, ne.Path, ne.Data = "keyboard", "/sys/kbd/"+kbd.KeyStr, kbd
+	case termbox.EventResize:
+		ne.Type, ne.Path, ne.Data = "window", "/sys/wnd/resize", termui.EvtWnd{Width: e.Width, Height: e.Height}
+	case termbox.EventError:
+		ne.Type, ne.Path, ne.Data = "error", "/sys/err", termui.EvtErr(e.Err)
+	case termbox.EventMouse:
+		m := termui.EvtMouse{X: e.MouseX, Y: e.MouseY}
+		// The moves of the mouse with a button pressed have no button.
+		if e.Mod&termbox.ModMotion == 0 {
+			m.Press = mouseButtons[e.Key]
+		}
+		ne.Type, ne.Path, ne.Data = "mouse", "/sys/mouse", m
+	case termbox.EventInterrupt:
+		ne.Type = "interrupt"
+	}
+
+	return ne
+}
+
+// keyboardEvent with the name of the key, the same as termui (for example "C-c", "<enter>" or "M-a").
+// Unlike termui, the key 0x7F is "<backspace>" instead of "C-8": most terminals send it for the backspace key.
+func keyboardEvent(e termbox.Event) termui.EvtKbd {
+	k, pre, mod := string(e.Ch), "", ""
+
+	if e.Mod == termbox.ModAlt {
+		mod = "M-"
+	}
+	if e.Ch == 0 {
+		if e.Key > 0xFFFF-12 {
+			k = "<f" + strconv.Itoa(0xFFFF-int(e.Key)+1) + ">"
+		} else if e.Key > 0xFFFF-25 {
+			ks := []string{"<insert>", "<delete>", "<home>", "<end>", "<previous>", "<next>", "<up>", "<down>", "<left>", "<right>"}
+			k = ks[0xFFFF-int(e.Key)-12]
+		}
+
+		if e.Key <= 0x7F {
+			pre = "C-"
+			k = string(rune('a' - 1 + int(e.Key)))
+			kmap := map[termbox.Key][2]string{
+				termbox.KeyCtrlSpace:     {"C-", "<space>"},
+				termbox.KeyBackspace:     {"", "<backspace>"},
+				termbox.KeyTab:           {"", "<tab>"},
+				termbox.KeyEnter:         {"", "<enter>"},
+				termbox.KeyEsc:           {"", "<escape>"},
+				termbox.KeyCtrlBackslash: {"C-", "\\"},
+				termbox.KeyCtrlSlash:     {"C-", "/"},
+				termbox.KeySpace:         {"", "<space>"},
+				termbox.KeyBackspace2:    {"", "<backspace>"},
+			}
+			if sk, ok := kmap[e.Key]; ok {
+				pre, k = sk[0], sk[1]
+			}
+		}
+	}
+
+	return termui.EvtKbd{KeyStr: pre + mod + k}
+}
+
+// render widgets on the terminal, in order: the last ones are drawn over the first ones.
+func render(bs ...termui.Bufferer) {
+	terminalLock.Lock()
+	defer terminalLock.Unlock()
+
+	for _, b := range bs {
+		buf := b.Buffer()
+		for p, c := range buf.CellMap {
+			if p.In(buf.Area) {
+				termbox.SetCell(p.X, p.Y, c.Ch, termbox.Attribute(c.Fg), termbox.Attribute(c.Bg))
+			}
+		}
+	}
+	termbox.Flush()
+}
+
+// clearTerminal with the background color of the theme.
+func clearTerminal() {
+	terminalLock.Lock()
+	defer terminalLock.Unlock()
+
+	termbox.Clear(termbox.ColorDefault, termbox.Attribute(termui.ThemeAttr("bg")))
+}
+
+func termWidth() int {
+	w, _ := termSize()
+	return w
+}
+
+func termHeight() int {
+	_, h := termSize()
+	return h
+}
+
+func termSize() (int, int) {
+	terminalLock.Lock()
+	defer terminalLock.Unlock()
+
+	termbox.Sync()
+	return termbox.Size()
+}
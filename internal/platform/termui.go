@@ -2,26 +2,122 @@ package platform
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/termui"
+	"github.com/nsf/termbox-go"
+)
+
+// Buttons of the mouse events, named after the keys of termbox.
+const (
+	mouseLeft      = "MouseLeft"
+	mouseWheelUp   = "MouseWheelUp"
+	mouseWheelDown = "MouseWheelDown"
 )
 
 type termUI struct {
+	mu      sync.Mutex
 	body    *termui.Grid
 	widgets []termui.GridBufferer
 	col     []*termui.Row
-	row     []*termui.Row
+
+	// cells of the grid, indexed by ID. The IDs are never reused, even after cleaning the grid.
+	cells    map[int]*cell
+	lastCell int
+	// target is the cell where the widgets are drawn. If nil, they're added to the current column.
+	target *cell
+	// status line at the bottom of the terminal. Not displayed if nil.
+	status *termui.Par
+
+	// focus is the position of the focused cell in the grid, starting at 1. No cell is focused if 0.
+	// The position is kept when the grid is rebuilt, to keep the focus on the same widget after a reload.
+	focus int
+	// scroll of the focused cell, in lines.
+	scroll int
+	// zoom display the focused cell on the whole terminal.
+	zoom bool
+
+	// overlay displayed over the dashboard. Not displayed if nil.
+	overlay *termui.Par
+
+	// tabs is the tab bar of the pages, and tabWidths the width of each tab. No tab bar if nil.
+	tabs      *termui.Par
+	tabWidths []int
+}
+
+// cell of the grid which content can be replaced without rebuilding the whole grid.
+type cell struct {
+	content termui.GridBufferer
+	x       int
+	y       int
+	width   int
+	focused bool
+	scroll  int
+	// actions of the rows of a table, with the same indexes as the rows without the header.
+	actions []func() error
+}
+
+func newCell() *cell {
+	b := termui.NewBlock()
+	b.Border = false
+	b.Height = 0
+
+	return &cell{content: b}
+}
+
+func (c *cell) Buffer() termui.Buffer {
+	w := view(c.content, c.scroll)
+	if c.focused && len(c.actions) > 0 {
+		w = selectRow(w)
+	}
+
+	buf := w.Buffer()
+	if c.focused {
+		highlight(buf)
+	}
+
+	return buf
+}
+
+func (c *cell) GetHeight() int {
+	return c.content.GetHeight()
+}
+
+func (c *cell) SetWidth(w int) {
+	c.width = w
+	c.content.SetWidth(w)
+}
+
+func (c *cell) SetX(x int) {
+	c.x = x
+	c.content.SetX(x)
+}
+
+func (c *cell) SetY(y int) {
+	c.y = y
+	c.content.SetY(y)
+}
+
+// set the content of the cell, at the position of the previous one.
+func (c *cell) set(w termui.GridBufferer) {
+	w.SetWidth(c.width)
+	w.SetX(c.x)
+	w.SetY(c.y)
+	c.content = w
+	c.actions = nil
 }
 
 // NewTermUI returns a new Terminal Interface object with a given output mode.
 func NewTermUI(d bool) (*termUI, error) {
-	if err := termui.Init(); err != nil {
+	if err := initTerminal(); err != nil {
 		return nil, err
 	}
 
 	termUI := termUI{
-		row: []*termui.Row{},
+		cells: map[int]*cell{},
 	}
 
 	termui.Handle("/sys/wnd/resize", func(e termui.Event) {
@@ -35,19 +131,145 @@ func NewTermUI(d bool) (*termUI, error) {
 
 // AddCol to the termui grid system.
 func (t *termUI) AddCol(size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.col = append(t.col, termui.NewCol(size, 0, t.widgets...))
 	t.widgets = []termui.GridBufferer{}
 }
 
 // AddRow to the termui grid system.
 func (t *termUI) AddRow() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.body.AddRows(termui.NewRow(t.col...))
-	t.Align()
+	// The columns are now part of the row.
+	t.col = []*termui.Row{}
+	t.align()
+}
+
+// AddCell to the current column and return its ID.
+func (t *termUI) AddCell() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastCell++
+	c := newCell()
+	t.cells[t.lastCell] = c
+	t.widgets = append(t.widgets, c)
+
+	return t.lastCell
+}
+
+// DrawCell replace the content of a cell with the widget drawn by the function draw.
+// Nothing is drawn if the cell doesn't exist anymore.
+func (t *termUI) DrawCell(id int, draw func() error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.cells[id]
+	if !ok {
+		return nil
+	}
+
+	t.target = c
+	defer func() { t.target = nil }()
+
+	return draw()
+}
+
+// add a widget to the targeted cell, or to the current column if there is no target.
+func (t *termUI) add(w termui.GridBufferer) {
+	if t.target != nil {
+		t.target.set(w)
+		return
+	}
+
+	t.widgets = append(t.widgets, w)
 }
 
 func (t *termUI) Align() {
-	t.body.Width = termui.TermWidth()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.align()
+}
+
+func (t *termUI) align() {
+	t.body.Width = termWidth()
 	t.body.Align()
+
+	if t.status != nil {
+		t.status.Width = termWidth()
+		t.status.Y = termHeight() - 1
+	}
+
+	if t.overlay != nil {
+		t.alignOverlay()
+	}
+}
+
+// MarkStale the widget of a cell: its border is muted and the label is added to its title.
+func (t *termUI) MarkStale(id int, label string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.cells[id]
+	if !ok {
+		return
+	}
+
+	b := block(c.content)
+	if b == nil {
+		return
+	}
+
+	b.BorderFg = termui.ColorBlack | termui.AttrBold
+	b.BorderLabelFg = termui.ColorBlack | termui.AttrBold
+	b.BorderLabel += label
+}
+
+// block of a widget, to change its border. Nil if the widget doesn't have any.
+func block(w termui.GridBufferer) *termui.Block {
+	switch v := w.(type) {
+	case *termui.Par:
+		return &v.Block
+	case *termui.Table:
+		return &v.Block
+	case *termui.BarChart:
+		return &v.Block
+	case *termui.MBarChart:
+		return &v.Block
+	case *termui.Gauge:
+		return &v.Block
+	}
+
+	return nil
+}
+
+// StatusLine display a text at the bottom of the terminal. Nothing is displayed if the text is empty.
+func (t *termUI) StatusLine(text string, textColor uint16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if text == "" {
+		if t.status != nil {
+			t.status = nil
+			clearTerminal()
+		}
+		return
+	}
+
+	if t.status == nil {
+		t.status = termui.NewPar("")
+		t.status.Border = false
+		t.status.Height = 1
+	}
+	t.status.Text = text
+	t.status.TextFgColor = termui.Attribute(textColor)
+	t.status.Width = termWidth()
+	t.status.Y = termHeight() - 1
 }
 
 // TextBox widget type.
@@ -77,7 +299,7 @@ func (t *termUI) TextBox(
 	textBox.Height = height
 	textBox.Multiline = multiline
 
-	t.widgets = append(t.widgets, textBox)
+	t.add(textBox)
 }
 
 func (t *termUI) Gauge(
@@ -99,7 +321,7 @@ func (t *termUI) Gauge(
 	gauge.Percent = data
 	gauge.Height = height
 
-	t.widgets = append(t.widgets, gauge)
+	t.add(gauge)
 }
 
 // Title is a special TextBox widget type.
@@ -112,6 +334,9 @@ func (t *termUI) Title(
 	height int,
 	size int,
 ) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	pro := termui.NewPar(title)
 	pro.TextFgColor = termui.Attribute(textColor)
 	if bold {
@@ -123,6 +348,31 @@ func (t *termUI) Title(
 	t.body.AddRows(termui.NewCol(size, 0, pro))
 }
 
+// TabBar display the tabs on one line, the current one highlighted.
+func (t *termUI) TabBar(tabs []string, current int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	text := ""
+	t.tabWidths = make([]int, len(tabs))
+	for k, v := range tabs {
+		// Each tab is displayed with a space before its name and two spaces after.
+		t.tabWidths[k] = len([]rune(v)) + 3
+		if k == current {
+			text += fmt.Sprintf("[ %s ](fg-black,bg-white) ", v)
+			continue
+		}
+		text += fmt.Sprintf(" %s  ", v)
+	}
+
+	bar := termui.NewPar(text)
+	bar.Border = false
+	bar.Height = 1
+	t.tabs = bar
+
+	t.body.AddRows(termui.NewCol(12, 0, bar))
+}
+
 // BarChar widget type.
 func (t *termUI) BarChart(
 	data []int,
@@ -153,7 +403,7 @@ func (t *termUI) BarChart(
 	bc.EmptyNumColor = termui.Attribute(enc)
 	bc.Buffer()
 
-	t.widgets = append(t.widgets, bc)
+	t.add(bc)
 }
 
 // StackedBarChar widget type.
@@ -187,7 +437,7 @@ func (t *termUI) StackedBarChart(
 	}
 	bc.NumColor = [8]termui.Attribute{termui.Attribute(nc), termui.Attribute(nc)}
 
-	t.widgets = append(t.widgets, bc)
+	t.add(bc)
 }
 
 // Table widget type.
@@ -197,6 +447,8 @@ func (t *termUI) Table(
 	tc uint16,
 	bd uint16,
 	fg uint16,
+	height int,
+	actions []func() error,
 ) {
 	ta := termui.NewTable()
 	ta.Rows = data
@@ -205,8 +457,21 @@ func (t *termUI) Table(
 	ta.BorderLabelFg = termui.Attribute(tc)
 	ta.BorderFg = termui.Attribute(bd)
 	ta.SetSize()
+	if height > 0 {
+		ta.Height = height
+	}
 
-	t.widgets = append(t.widgets, ta)
+	t.add(ta)
+	if t.target != nil {
+		t.target.actions = actions
+	}
+}
+
+// KAction set a key to run an action.
+func (*termUI) KAction(key string, action func()) {
+	termui.Handle(fmt.Sprintf("/sys/kbd/%s", key), func(termui.Event) {
+		go action()
+	})
 }
 
 // KQuit set a key to quit the application.
@@ -226,6 +491,37 @@ func (t *termUI) KHotReload(key string, c chan<- time.Time) {
 	})
 }
 
+// Mouse enable the mouse, and call click with the position of each click and each move of the wheel.
+// The wheel is -1 when it's moved up, 1 when it's moved down, and 0 for a click.
+func (t *termUI) Mouse(click func(x, y, wheel int)) {
+	termbox.SetInputMode(termbox.InputEsc | termbox.InputMouse)
+	termui.Handle("/sys/mouse", func(e termui.Event) {
+		if x, y, wheel, ok := mouseClick(e); ok {
+			go click(x, y, wheel)
+		}
+	})
+}
+
+// mouseClick of an event of the mouse: its position, and the direction of the wheel (0 for a click).
+// The releases of the buttons and the other buttons are ignored.
+func mouseClick(e termui.Event) (x, y, wheel int, ok bool) {
+	m, ok := e.Data.(termui.EvtMouse)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	switch m.Press {
+	case mouseWheelUp:
+		return m.X, m.Y, -1, true
+	case mouseWheelDown:
+		return m.X, m.Y, 1, true
+	case mouseLeft:
+		return m.X, m.Y, 0, true
+	}
+
+	return 0, 0, 0, false
+}
+
 // Key to edit a dashboard config.
 // Need to stop the hot reload while editing the file.
 // Automatically reload the dashboad after the edit is done.
@@ -240,34 +536,468 @@ func (t *termUI) Loop() {
 	termui.Loop()
 }
 
-// Render termui and delete the instance of the widgets rendered.
-func (t *termUI) Render() {
-	termui.Render(t.body)
+// Focus the cell at some offset of the one currently focused. A negative offset focus the previous cells.
+// The focus goes back to the first cell after the last one.
+func (t *termUI) Focus(offset int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.cells)
+	if n == 0 {
+		return
+	}
+
+	if t.focus == 0 && offset < 0 {
+		t.focus = n + 1
+	}
+	t.focus = ((t.focus-1+offset)%n+n)%n + 1
+	t.scroll = 0
 
-	// delete every widget for the rows / cols rendered.
-	t.removeWidgets()
+	if t.zoom {
+		clearTerminal()
+	}
 }
 
-func (t *termUI) removeWidgets() {
-	t.row = []*termui.Row{}
-	t.col = []*termui.Row{}
+// Scroll the content of the focused cell by some lines. A negative number of lines scroll up.
+func (t *termUI) Scroll(lines int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.scrollBy(lines)
+}
+
+func (t *termUI) scrollBy(lines int) {
+	c := t.focused()
+	if c == nil {
+		return
+	}
+
+	// The cells at the bottom of the grid can be cut by the terminal.
+	height := termHeight() - c.y
+	if c.GetHeight() < height {
+		height = c.GetHeight()
+	}
+	if t.zoom {
+		height = t.zoomHeight()
+	}
+
+	// The row on top of a table with actions is selected: every row can be scrolled to the top.
+	limit := maxScroll(c.content, height)
+	if len(c.actions) > 0 {
+		limit = len(c.actions) - 1
+	}
+
+	t.scroll += lines
+	if t.scroll > limit {
+		t.scroll = limit
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+}
+
+// Click at a position of the terminal, or move the wheel there (-1 up, 1 down, 0 for a click).
+// A click focus the cell clicked. The wheel focus the cell under the pointer and scroll it.
+// Return the index of the tab clicked, or -1 if no tab is clicked.
+func (t *termUI) Click(x, y, wheel int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tab := t.click(x, y, wheel)
+	if wheel != 0 {
+		t.scrollBy(wheel)
+	}
+
+	return tab
+}
+
+// click focus the cell at a position, and return the index of the tab clicked, or -1 if no tab is clicked.
+// The cell displayed on the whole terminal stays focused.
+func (t *termUI) click(x, y, wheel int) int {
+	if tab := t.tabAt(x, y); tab >= 0 && wheel == 0 {
+		return tab
+	}
+
+	if t.zoom {
+		return -1
+	}
+
+	if id := t.cellAt(x, y); id != 0 && id != t.focusedID() {
+		t.focus = t.position(id)
+		t.scroll = 0
+	}
+
+	return -1
+}
+
+// tabAt a position, or -1 if there is no tab.
+func (t *termUI) tabAt(x, y int) int {
+	if t.tabs == nil || y != t.tabs.Y {
+		return -1
+	}
+
+	start := t.tabs.X
+	for k, w := range t.tabWidths {
+		if x >= start && x < start+w {
+			return k
+		}
+		start += w
+	}
+
+	return -1
+}
+
+// cellAt a position, or 0 if there is none.
+func (t *termUI) cellAt(x, y int) int {
+	for id, c := range t.cells {
+		if x >= c.x && x < c.x+c.width && y >= c.y && y < c.y+c.GetHeight() {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// position of a cell in the grid, starting at 1.
+func (t *termUI) position(id int) int {
+	ids := t.sortedIDs()
+	for k, v := range ids {
+		if v == id {
+			return k + 1
+		}
+	}
+
+	return 0
+}
+
+// Zoom display the focused cell on the whole terminal, or display the whole grid again if it's already zoomed.
+func (t *termUI) Zoom() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.focused() == nil && !t.zoom {
+		return
+	}
+
+	t.zoom = !t.zoom
+	t.scroll = 0
+	clearTerminal()
+}
+
+// Focused return the ID of the focused cell, or 0 if no cell is focused.
+func (t *termUI) Focused() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.focusedID()
+}
+
+// RowAction return the action of the selected row of the focused cell, or nil if there is none.
+// The selected row is the first one displayed.
+func (t *termUI) RowAction() func() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.focused()
+	if c == nil || t.scroll >= len(c.actions) {
+		return nil
+	}
+
+	return c.actions[t.scroll]
+}
+
+// Overlay display a text in a box over the dashboard. The box is removed if the text is empty.
+func (t *termUI) Overlay(title string, text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if text == "" {
+		if t.overlay != nil {
+			t.overlay = nil
+			clearTerminal()
+		}
+		return
+	}
+
+	t.overlay = termui.NewPar(text)
+	t.overlay.BorderLabel = title
+	t.overlay.BorderFg = termui.ColorYellow | termui.AttrBold
+	t.overlay.BorderLabelFg = termui.ColorYellow | termui.AttrBold
+	t.alignOverlay()
+}
+
+// alignOverlay in the middle of the terminal, as big as its text.
+func (t *termUI) alignOverlay() {
+	lines := strings.Split(t.overlay.Text, "\n")
+	width := 0
+	for _, l := range lines {
+		if len([]rune(l)) > width {
+			width = len([]rune(l))
+		}
+	}
+
+	t.overlay.Width = width + 4
+	if t.overlay.Width > termWidth() {
+		t.overlay.Width = termWidth()
+	}
+	// The lines too long for the terminal are wrapped.
+	height, inner := 0, t.overlay.Width-3
+	for _, l := range lines {
+		height += 1
+		if n := len([]rune(l)); inner > 0 && n > inner {
+			height += (n - 1) / inner
+		}
+	}
+
+	t.overlay.Height = height + 2
+	if t.overlay.Height > termHeight() {
+		t.overlay.Height = termHeight()
+	}
+	t.overlay.PaddingLeft = 1
+	t.overlay.X = (termWidth() - t.overlay.Width) / 2
+	t.overlay.Y = (termHeight() - t.overlay.Height) / 2
+}
+
+// focused cell. Nil if there is none.
+func (t *termUI) focused() *cell {
+	return t.cells[t.focusedID()]
+}
+
+func (t *termUI) focusedID() int {
+	if t.focus < 1 || t.focus > len(t.cells) {
+		return 0
+	}
+
+	return t.sortedIDs()[t.focus-1]
+}
+
+func (t *termUI) sortedIDs() []int {
+	ids := make([]int, 0, len(t.cells))
+	for id := range t.cells {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+func (t *termUI) zoomHeight() int {
+	if t.status != nil {
+		return termHeight() - 1
+	}
+
+	return termHeight()
+}
+
+// view of a widget scrolled by some lines. The widget is copied if its content needs to change.
+func view(w termui.GridBufferer, scroll int) termui.GridBufferer {
+	if scroll < 0 {
+		scroll = 0
+	}
+
+	switch v := w.(type) {
+	case *termui.Par:
+		lines := strings.Split(v.Text, "\n")
+		if scroll == 0 {
+			return w
+		}
+		if scroll >= len(lines) {
+			scroll = len(lines) - 1
+		}
+		p := *v
+		p.Text = strings.Join(lines[scroll:], "\n")
+		return &p
+	case *termui.Table:
+		visible := visibleRows(v, v.Height)
+		if len(v.Rows) < 2 || (scroll == 0 && len(v.Rows)-1 <= visible) {
+			return w
+		}
+		if scroll > len(v.Rows)-2 {
+			scroll = len(v.Rows) - 2
+		}
+		end := len(v.Rows)
+		if 1+scroll+visible < end {
+			end = 1 + scroll + visible
+		}
+		// The header stays on top, and the rows which don't fit are not drawn over the border.
+		ta := *v
+		ta.Rows = append([][]string{v.Rows[0]}, v.Rows[1+scroll:end]...)
+		ta.FgColors = nil
+		ta.BgColors = nil
+		return &ta
+	}
+
+	return w
+}
+
+// selectRow display the first row of a table, after the header, in reverse colors.
+func selectRow(w termui.GridBufferer) termui.GridBufferer {
+	v, ok := w.(*termui.Table)
+	if !ok || len(v.Rows) < 2 {
+		return w
+	}
+
+	ta := *v
+	ta.FgColors = make([]termui.Attribute, len(v.Rows))
+	ta.BgColors = make([]termui.Attribute, len(v.Rows))
+	ta.FgColors[1] = termui.ColorBlack
+	ta.BgColors[1] = termui.ColorWhite
+
+	return &ta
+}
+
+// maxScroll of a widget displayed with the height given.
+func maxScroll(w termui.GridBufferer, height int) int {
+	limit := 0
+	switch v := w.(type) {
+	case *termui.Par:
+		limit = len(strings.Split(v.Text, "\n")) - (height - 2)
+	case *termui.Table:
+		limit = len(v.Rows) - 1 - visibleRows(v, height)
+	}
+
+	if limit < 0 {
+		return 0
+	}
+
+	return limit
+}
+
+// visibleRows of a table with the height given, without the header.
+func visibleRows(t *termui.Table, height int) int {
+	// Minus the borders and the header.
+	visible := height - 3
+	if t.Separator {
+		visible = (height-1)/2 - 1
+	}
+
+	if visible < 0 {
+		return 0
+	}
+
+	return visible
+}
+
+// zoomed copy of a widget, on the whole area given.
+func zoomed(w termui.GridBufferer, width int, height int) termui.GridBufferer {
+	var z termui.GridBufferer
+	switch v := w.(type) {
+	case *termui.Par:
+		c := *v
+		z = &c
+	case *termui.Table:
+		c := *v
+		z = &c
+	case *termui.BarChart:
+		c := *v
+		z = &c
+	case *termui.MBarChart:
+		c := *v
+		z = &c
+	case *termui.Gauge:
+		c := *v
+		z = &c
+	default:
+		return w
+	}
+
+	block(z).Height = height
+	z.SetWidth(width)
+	z.SetX(0)
+	z.SetY(0)
+
+	return z
+}
+
+// doubleBorders replace the borders of the widgets to highlight them.
+var doubleBorders = map[rune]rune{
+	'─': '═',
+	'│': '║',
+	'┌': '╔',
+	'┐': '╗',
+	'└': '╚',
+	'┘': '╝',
+}
+
+// highlight the border of a widget with double lines.
+func highlight(buf termui.Buffer) {
+	a := buf.Area
+	for p, c := range buf.CellMap {
+		if p.X != a.Min.X && p.X != a.Max.X-1 && p.Y != a.Min.Y && p.Y != a.Max.Y-1 {
+			continue
+		}
+		if d, ok := doubleBorders[c.Ch]; ok {
+			c.Ch = d
+			c.Fg |= termui.AttrBold
+			buf.CellMap[p] = c
+		}
+	}
+}
+
+// Render termui.
+func (t *termUI) Render() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range t.cells {
+		c.focused = false
+		c.scroll = 0
+	}
+
+	f := t.focused()
+	if f != nil {
+		f.focused = true
+		f.scroll = t.scroll
+	}
+
+	if t.zoom && f != nil {
+		z := view(zoomed(f.content, termWidth(), t.zoomHeight()), t.scroll)
+		if len(f.actions) > 0 {
+			z = selectRow(z)
+		}
+		render(z)
+	} else {
+		render(t.body)
+	}
+
+	if t.status != nil {
+		render(t.status)
+	}
+
+	if t.overlay != nil {
+		render(t.overlay)
+	}
 }
 
 // Clean and create a new empty grid.
 func (t *termUI) Clean() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clean()
+}
+
+func (t *termUI) clean() {
+	t.widgets = []termui.GridBufferer{}
+	t.col = []*termui.Row{}
+	t.cells = map[int]*cell{}
+	t.tabs, t.tabWidths = nil, nil
 	t.body = termui.NewGrid()
 	t.body.X = 0
 	t.body.Y = 0
 	t.body.BgColor = termui.ThemeAttr("bg")
-	t.body.Width = termui.TermWidth()
+	t.body.Width = termWidth()
 }
 
 // Close termui.
-func (termUI) Close() {
-	termui.Close()
+func (t *termUI) Close() {
+	termbox.Close()
 }
 
 func (t *termUI) HotReload() {
-	t.Clean()
-	termui.Clear()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clean()
+	clearTerminal()
 }
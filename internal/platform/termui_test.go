@@ -0,0 +1,338 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Phantas0s/termui"
+	"github.com/nsf/termbox-go"
+)
+
+func Test_maxScroll(t *testing.T) {
+	table := termui.NewTable()
+	table.Rows = [][]string{{"header"}, {"1"}, {"2"}, {"3"}, {"4"}, {"5"}}
+
+	testCases := []struct {
+		name     string
+		widget   termui.GridBufferer
+		height   int
+		expected int
+	}{
+		{
+			name:     "table higher than its rows",
+			widget:   table,
+			height:   20,
+			expected: 0,
+		},
+		{
+			name:     "table with two rows visible",
+			widget:   table,
+			height:   7,
+			expected: 3,
+		},
+		{
+			name:     "text with one line visible",
+			widget:   termui.NewPar("1\n2\n3"),
+			height:   3,
+			expected: 2,
+		},
+		{
+			name:     "gauge",
+			widget:   termui.NewGauge(),
+			height:   3,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := maxScroll(tc.widget, tc.height)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_view(t *testing.T) {
+	rows := [][]string{{"header"}, {"1"}, {"2"}, {"3"}}
+
+	testCases := []struct {
+		name     string
+		height   int
+		scroll   int
+		expected [][]string
+	}{
+		{
+			name:     "not scrolled",
+			height:   9,
+			scroll:   0,
+			expected: [][]string{{"header"}, {"1"}, {"2"}, {"3"}},
+		},
+		{
+			name:     "scrolled under the header",
+			height:   9,
+			scroll:   2,
+			expected: [][]string{{"header"}, {"3"}},
+		},
+		{
+			name:     "scrolled after the last row",
+			height:   9,
+			scroll:   10,
+			expected: [][]string{{"header"}, {"3"}},
+		},
+		{
+			name:     "rows not fitting in the table",
+			height:   5,
+			scroll:   1,
+			expected: [][]string{{"header"}, {"2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table := termui.NewTable()
+			table.Rows = rows
+			table.Height = tc.height
+
+			actual := view(table, tc.scroll).(*termui.Table).Rows
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+
+			if len(table.Rows) != len(rows) {
+				t.Errorf("Expected the table to be unchanged, actual %v", table.Rows)
+			}
+		})
+	}
+}
+
+func Test_tabAt(t *testing.T) {
+	bar := termui.NewPar("")
+	bar.Y = 3
+	ui := &termUI{tabs: bar, tabWidths: []int{len("first") + 3, len("second") + 3}}
+
+	testCases := []struct {
+		name     string
+		x        int
+		y        int
+		expected int
+	}{
+		{
+			name:     "first tab",
+			x:        0,
+			y:        3,
+			expected: 0,
+		},
+		{
+			name:     "last character of the first tab",
+			x:        7,
+			y:        3,
+			expected: 0,
+		},
+		{
+			name:     "second tab",
+			x:        8,
+			y:        3,
+			expected: 1,
+		},
+		{
+			name:     "after the tabs",
+			x:        17,
+			y:        3,
+			expected: -1,
+		},
+		{
+			name:     "other line",
+			x:        0,
+			y:        4,
+			expected: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ui.tabAt(tc.x, tc.y)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_mouseClick(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    termbox.Event
+		expected []int
+		ok       bool
+	}{
+		{
+			name:     "click",
+			event:    termbox.Event{Type: termbox.EventMouse, Key: termbox.MouseLeft, MouseX: 3, MouseY: 4},
+			expected: []int{3, 4, 0},
+			ok:       true,
+		},
+		{
+			name:     "wheel up",
+			event:    termbox.Event{Type: termbox.EventMouse, Key: termbox.MouseWheelUp, MouseX: 3, MouseY: 4},
+			expected: []int{3, 4, -1},
+			ok:       true,
+		},
+		{
+			name:     "wheel down",
+			event:    termbox.Event{Type: termbox.EventMouse, Key: termbox.MouseWheelDown, MouseX: 3, MouseY: 4},
+			expected: []int{3, 4, 1},
+			ok:       true,
+		},
+		{
+			name:     "release",
+			event:    termbox.Event{Type: termbox.EventMouse, Key: termbox.MouseRelease, MouseX: 3, MouseY: 4},
+			expected: []int{0, 0, 0},
+		},
+		{
+			name:     "other button",
+			event:    termbox.Event{Type: termbox.EventMouse, Key: termbox.MouseRight, MouseX: 3, MouseY: 4},
+			expected: []int{0, 0, 0},
+		},
+		{
+			name: "move with a button pressed",
+			event: termbox.Event{
+				Type:   termbox.EventMouse,
+				Key:    termbox.MouseLeft,
+				Mod:    termbox.ModMotion,
+				MouseX: 3,
+				MouseY: 4,
+			},
+			expected: []int{0, 0, 0},
+		},
+		{
+			name:     "key",
+			event:    termbox.Event{Type: termbox.EventKey, Key: termbox.KeyEnter},
+			expected: []int{0, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y, wheel, ok := mouseClick(termboxEvent(tc.event))
+			actual := []int{x, y, wheel}
+			if !reflect.DeepEqual(tc.expected, actual) || ok != tc.ok {
+				t.Errorf("Expected %v and %v, actual %v and %v", tc.expected, tc.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func Test_termboxEvent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    termbox.Event
+		expected string
+	}{
+		{name: "character", event: termbox.Event{Type: termbox.EventKey, Ch: 'q'}, expected: "/sys/kbd/q"},
+		{name: "control", event: termbox.Event{Type: termbox.EventKey, Key: termbox.KeyCtrlC}, expected: "/sys/kbd/C-c"},
+		{name: "enter", event: termbox.Event{Type: termbox.EventKey, Key: termbox.KeyEnter}, expected: "/sys/kbd/<enter>"},
+		{name: "backspace", event: termbox.Event{Type: termbox.EventKey, Key: termbox.KeyBackspace}, expected: "/sys/kbd/<backspace>"},
+		{
+			name:     "backspace of most terminals",
+			event:    termbox.Event{Type: termbox.EventKey, Key: termbox.KeyBackspace2},
+			expected: "/sys/kbd/<backspace>",
+		},
+		{name: "arrow", event: termbox.Event{Type: termbox.EventKey, Key: termbox.KeyArrowUp}, expected: "/sys/kbd/<up>"},
+		{name: "function", event: termbox.Event{Type: termbox.EventKey, Key: termbox.KeyF5}, expected: "/sys/kbd/<f5>"},
+		{
+			name:     "alt",
+			event:    termbox.Event{Type: termbox.EventKey, Ch: 'a', Mod: termbox.ModAlt},
+			expected: "/sys/kbd/M-a",
+		},
+		{name: "resize", event: termbox.Event{Type: termbox.EventResize}, expected: "/sys/wnd/resize"},
+		{name: "mouse", event: termbox.Event{Type: termbox.EventMouse}, expected: "/sys/mouse"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := termboxEvent(tc.event).Path
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_click(t *testing.T) {
+	bar := termui.NewPar("")
+	bar.Y = 10
+
+	testCases := []struct {
+		name          string
+		focus         int
+		zoom          bool
+		y             int
+		wheel         int
+		expectedTab   int
+		expectedFocus int
+	}{
+		{
+			name:          "click on a cell",
+			focus:         1,
+			y:             6,
+			expectedTab:   -1,
+			expectedFocus: 2,
+		},
+		{
+			name:          "click on the focused cell",
+			focus:         2,
+			y:             8,
+			expectedTab:   -1,
+			expectedFocus: 2,
+		},
+		{
+			name:          "wheel on a cell",
+			focus:         1,
+			y:             6,
+			wheel:         1,
+			expectedTab:   -1,
+			expectedFocus: 2,
+		},
+		{
+			name:          "click on a cell of a zoomed dashboard",
+			focus:         1,
+			zoom:          true,
+			y:             6,
+			expectedTab:   -1,
+			expectedFocus: 1,
+		},
+		{
+			name:          "click on a tab",
+			focus:         1,
+			y:             10,
+			expectedTab:   0,
+			expectedFocus: 1,
+		},
+		{
+			name:          "wheel on a tab",
+			focus:         1,
+			y:             10,
+			wheel:         1,
+			expectedTab:   -1,
+			expectedFocus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &termUI{cells: map[int]*cell{}, focus: tc.focus, zoom: tc.zoom, tabs: bar, tabWidths: []int{10}}
+			for id, y := range map[int]int{1: 0, 2: 5} {
+				p := termui.NewPar("")
+				p.Height = 5
+				ui.cells[id] = &cell{content: p, y: y, width: 10}
+			}
+
+			tab := ui.click(1, tc.y, tc.wheel)
+			if tab != tc.expectedTab || ui.focus != tc.expectedFocus {
+				t.Errorf("Expected %v and %v, actual %v and %v", tc.expectedTab, tc.expectedFocus, tab, ui.focus)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package platform
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/shuheiktgw/go-travis"
@@ -23,7 +24,8 @@ func NewTravisCI(token string) *TravisCI {
 	}
 }
 
-func (tc TravisCI) Builds(repository string, owner string, limit int64) ([][]string, error) {
+// Builds of a repository, or of every repository if the repository or its owner is empty, with the URL of each build.
+func (tc TravisCI) Builds(ctx context.Context, repository string, owner string, limit int64) ([][]string, []string, error) {
 	include := []string{
 		"build.repository",
 		"build.state",
@@ -36,18 +38,18 @@ func (tc TravisCI) Builds(repository string, owner string, limit int64) ([][]str
 	var err error
 	if repository == "" || owner == "" {
 		builds, _, err = tc.client.Builds.List(
-			context.Background(),
+			ctx,
 			&travis.BuildsOption{
 				Include: include,
 				Limit:   int(limit),
 			},
 		)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	} else {
 		builds, _, err = tc.client.Builds.ListByRepoSlug(
-			context.Background(),
+			ctx,
 			createRepoName(repository, owner),
 			&travis.BuildsByRepoOption{
 				Include: include,
@@ -56,17 +58,19 @@ func (tc TravisCI) Builds(repository string, owner string, limit int64) ([][]str
 			},
 		)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	table := formatBuilds(builds, limit)
+	table, urls := formatBuilds(builds, limit)
 
-	return table, nil
+	return table, urls, nil
 }
 
-func formatBuilds(builds []*travis.Build, limit int64) [][]string {
+// formatBuilds in a table, and return the URLs of the builds with the same indexes as the rows without the header.
+func formatBuilds(builds []*travis.Build, limit int64) ([][]string, []string) {
 	table := make([][]string, limit+1)
+	urls := make([]string, limit)
 
 	table[0] = []string{
 		"Repository",
@@ -91,12 +95,28 @@ func formatBuilds(builds []*travis.Build, limit int64) [][]string {
 			} else {
 				table[k+1] = append(table[k+1], "Running")
 			}
+			urls[k] = buildURL(v)
 		}
 	}
 
-	return table
+	return table, urls
+}
+
+// buildURL on the Travis CI website. Empty if the build doesn't have its ID or the slug of its repository.
+func buildURL(b *travis.Build) string {
+	if b.Id == nil || b.Repository == nil || b.Repository.Slug == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("https://travis-ci.org/%s/builds/%d", *b.Repository.Slug, *b.Id)
 }
 
 func createRepoName(repository string, owner string) string {
 	return owner + "/" + repository
 }
+
+// CheckToken of the client, by fetching the user of the token.
+func (tc TravisCI) CheckToken(ctx context.Context) error {
+	_, _, err := tc.client.User.Current(ctx, nil)
+	return err
+}
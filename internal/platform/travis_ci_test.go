@@ -10,10 +10,11 @@ import (
 
 func Test_formatBuilds(t *testing.T) {
 	testCases := []struct {
-		name        string
-		fixtureFile string
-		expected    [][]string
-		limit       int64
+		name         string
+		fixtureFile  string
+		expected     [][]string
+		expectedURLs []string
+		limit        int64
 	}{
 		// TODO test empty array!
 		{
@@ -38,6 +39,10 @@ func Test_formatBuilds(t *testing.T) {
 					"2019-10-10T19:02:03Z",
 				},
 			},
+			expectedURLs: []string{
+				"https://travis-ci.org/Phantas0s/devdash/builds/596709995",
+				"https://travis-ci.org/Phantas0s/devdash/builds/596259298",
+			},
 			fixtureFile: "./testdata/fixtures/travis_table_builds.json",
 			limit:       2,
 		},
@@ -52,11 +57,15 @@ func Test_formatBuilds(t *testing.T) {
 				t.Error(err)
 			}
 
-			actual := formatBuilds(tb, tc.limit)
+			actual, urls := formatBuilds(tb, tc.limit)
 
 			if !reflect.DeepEqual(tc.expected, actual) {
 				t.Errorf("Expected %v, actual %v", tc.expected, actual)
 			}
+
+			if !reflect.DeepEqual(tc.expectedURLs, urls) {
+				t.Errorf("Expected %v, actual %v", tc.expectedURLs, urls)
+			}
 		})
 	}
 }
@@ -2,18 +2,14 @@ package internal
 
 // Dispatch the different widgets from the config to their widget managers and get back the render functions.
 
-// TODO it's a mess in there between concurrent / non concurrent ways of getting widget render functions.
-// TODO Initially it was made to go around the limitation of concurrent connection for Google Analytics.
-// TODO I feel the absence of generics here...  To refactor somehow (using reflection?).
-
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
-type service interface {
-	CreateWidgets(widget Widget, tui *Tui) (f func() error, err error)
-}
-
 type project struct {
 	name        string
 	nameOptions map[string]string
@@ -21,16 +17,11 @@ type project struct {
 	sizes       [][]string
 	themes      map[string]map[string]string
 	tui         *Tui
-
-	gaWidget         service
-	monitorWidget    service
-	gscWidget        service
-	githubWidget     service
-	travisCIWidget   service
-	feedlyWidget     service
-	gitWidget        service
-	remoteHostWidget service
-	localhostWidget  service
+	services    map[string]service
+	timeout     time.Duration
+	cache       *Cache
+	// cells of the grid where the widgets are rendered, with the same indexes as the widgets.
+	cells [][][]int
 }
 
 // NewProject for the dashboard.
@@ -49,46 +40,30 @@ func NewProject(
 		sizes:       sizes,
 		themes:      themes,
 		tui:         tui,
+		services:    map[string]service{},
 	}
 }
 
-// Service builder
-func (p *project) WithGa(ga *gaWidget) {
-	p.gaWidget = ga
-}
-
-func (p *project) WithMonitor(mon *monitorWidget) {
-	p.monitorWidget = mon
-}
-
-func (p *project) WithGoogleSearchConsole(gsc *gscWidget) {
-	p.gscWidget = gsc
-}
-
-func (p *project) WithGithub(github *githubWidget) {
-	p.githubWidget = github
-}
-
-func (p *project) WithTravisCI(travisCI *travisCIWidget) {
-	p.travisCIWidget = travisCI
-}
-
-func (p *project) WithFeedly(feedly *feedlyWidget) {
-	p.feedlyWidget = feedly
-}
-
-func (p *project) WithGit(git *gitWidget) {
-	p.gitWidget = git
+// WithServices add the services the widgets of the project can use, indexed by service ID.
+func (p *project) WithServices(services map[string]service) {
+	for k, v := range services {
+		p.services[k] = v
+	}
 }
 
-func (p *project) WithRemoteHost(remoteHost *HostWidget) {
-	p.remoteHostWidget = remoteHost
+// WithTimeout set the maximum duration to fetch the data of each widget.
+// The option "timeout" of a widget overrides it.
+func (p *project) WithTimeout(timeout time.Duration) {
+	p.timeout = timeout
 }
 
-func (p *project) WithLocalhost(localhost *HostWidget) {
-	p.localhostWidget = localhost
+// WithCache set the cache for the responses of the services.
+func (p *project) WithCache(c *Cache) {
+	p.cache = c
 }
 
+// addDefaultTheme to the options of the widget. The options set by the widget take precedence.
+// The widget gets its own copy of the options, the services being free to change them.
 func (p *project) addDefaultTheme(w Widget) Widget {
 	t := w.typeID()
 
@@ -103,208 +78,224 @@ func (p *project) addDefaultTheme(w Widget) Widget {
 		}
 	}
 
-	if len(theme) > 0 {
-		for k, v := range theme {
-			if len(w.Options) == 0 {
-				w.Options = map[string]string{}
-			}
-			if _, ok := w.Options[k]; !ok {
-				w.Options[k] = v
-			}
-		}
+	if len(w.Options) == 0 && len(theme) == 0 {
+		return w
+	}
+
+	options := make(map[string]string, len(w.Options)+len(theme))
+	for k, v := range theme {
+		options[k] = v
 	}
+	for k, v := range w.Options {
+		options[k] = v
+	}
+	w.Options = options
 
 	return w
 }
 
+// mapServiceID return the service of the project for a service ID, which can include the name of an instance.
+// The service is nil if it's registered but not configured in the project.
 func (p *project) mapServiceID(serviceID string) (service, error) {
-	services := map[string]service{
-		"display": NewDisplayWidget(),
-		"ga":      p.gaWidget,
-		"mon":     p.monitorWidget,
-		"gsc":     p.gscWidget,
-		"github":  p.githubWidget,
-		"travis":  p.travisCIWidget,
-		"feedly":  p.feedlyWidget,
-		"git":     p.gitWidget,
-		"rh":      p.remoteHostWidget,
-		"lh":      p.localhostWidget,
-	}
-
-	if _, ok := services[serviceID]; ok {
-		return services[serviceID], nil
+	serviceType, _ := splitInstance(serviceID)
+	if _, err := lookupService(serviceType); err != nil {
+		return nil, err
 	}
 
-	return nil, errors.Errorf("Impossible to find the service with ID %s", serviceID)
+	return p.services[serviceID], nil
 }
 
 func mapServiceName(serviceID string) (string, error) {
-	services := map[string]string{
-		"display": "Display",
-		"ga":      "Google Analytics",
-		"mon":     "Monitor",
-		"gsc":     "Google Search Console",
-		"github":  "Github",
-		"travis":  "Travis",
-		"feedly":  "Feedly",
-		"git":     "Git",
-		"rh":      "Remote Host",
-		"lh":      "Localhost",
+	serviceType, instance := splitInstance(serviceID)
+	def, err := lookupService(serviceType)
+	if err != nil {
+		return "", err
 	}
 
-	if _, ok := services[serviceID]; ok {
-		return services[serviceID], nil
+	return serviceName(def, instance), nil
+}
+
+// CreateWidgets populate the widgets with data, concurrently, with the workers of the scheduler.
+// Each widget is drawn in its cell as soon as its data arrive, without waiting for the others.
+// Nothing is drawn anymore when the context of the scheduler is done.
+func (p *project) CreateWidgets(s *Scheduler) {
+	for r, row := range p.widgets {
+		for c, col := range row {
+			for i, w := range col {
+				cell, key, w := p.cell(r, c, i), p.widgetKey(r, c, i), w
+				s.Go(w.serviceID(), func(ctx context.Context) {
+					p.fetchAndDraw(ctx, cell, key, w)
+				})
+			}
+		}
 	}
+}
 
-	return "", errors.Errorf("Impossible to find the service with ID %s", serviceID)
+// fetchAndDraw a widget in its cell.
+// If the widget is drawn with expired data from the cache, the data is fetched again and the widget redrawn.
+func (p *project) fetchAndDraw(ctx context.Context, cell int, key string, w Widget) {
+	cacheCtx, state := withCache(ctx, p.cache, false)
+	f, err := p.timedFetch(cacheCtx, cell, w)
+	p.draw(ctx, cell, key, f, err)
+
+	if state.isStale() {
+		cacheCtx, _ = withCache(ctx, p.cache, true)
+		f, err = p.timedFetch(cacheCtx, cell, w)
+		p.draw(ctx, cell, key, f, err)
+	}
 }
 
-// Create all the widgets and populate them with data.
-// Return channels with render functions
-func (p *project) CreateWidgets() [][][]func() error {
-	// TODO: use display.box instead of this shortcut
-	err := p.addTitle(p.tui)
-	if err != nil {
-		err = errors.Wrapf(err, "can't add project title %s", p.name)
-		DisplayError(p.tui, err)()
+// refresh the widget of a cell, using the data from the cache only if they're not expired.
+// If force is true, the data are never taken from the cache.
+func (p *project) refresh(ctx context.Context, cell int, w Widget, force bool) (func() error, error) {
+	cacheCtx, state := withCache(ctx, p.cache, force)
+	f, err := p.timedFetch(cacheCtx, cell, w)
+
+	if state.isStale() {
+		cacheCtx, _ = withCache(ctx, p.cache, true)
+		f, err = p.timedFetch(cacheCtx, cell, w)
 	}
 
-	chs := make([][][]chan func() error, len(p.widgets))
+	return f, err
+}
 
-	for ir, row := range p.widgets {
-		for ic, col := range row {
-			chs[ir] = append(chs[ir], []chan func() error{})
-			for _, w := range col {
-				w = p.addDefaultTheme(w)
-				ch := make(chan func() error)
-				chs[ir][ic] = append(chs[ir][ic], ch)
-
-				service, err := p.mapServiceID(w.serviceID())
-				if err != nil {
-					go func(c chan<- func() error) {
-						c <- DisplayError(p.tui, err)
-					}(ch)
-					continue
-				}
+// timedFetch the data of the widget of a cell, and record when they were fetched and how long it took.
+func (p *project) timedFetch(ctx context.Context, cell int, w Widget) (func() error, error) {
+	start := time.Now()
+	f, err := p.fetch(ctx, w)
+	p.tui.RecordFetch(cell, start, time.Since(start))
 
-				serviceName, err := mapServiceName(w.serviceID())
-				if err != nil {
-					go func(c chan<- func() error) {
-						c <- DisplayError(p.tui, err)
-					}(ch)
-					continue
-				}
+	return f, err
+}
 
-				go getChannelRenderers(service, serviceName, w, p.tui, ch)
-			}
-		}
+// fetch information via different ways depending on Widget (API / SSH / ...)
+// Return a function to display the widget, or the error if the data couldn't be fetched.
+// The fetching is interrupted when the context is done or when the timeout is reached.
+func (p *project) fetch(ctx context.Context, w Widget) (func() error, error) {
+	if err := checkWidgetOptions(w); err != nil {
+		return nil, err
 	}
+	w = p.addDefaultTheme(w)
 
-	funcs := make([][][]func() error, len(chs))
-	for r, _ := range chs {
-		for c, _ := range chs[r] {
-			funcs[r] = append(funcs[r], []func() error{})
-			for _, chann := range chs[r][c] {
-				f, ok := <-chann
-				if ok {
-					funcs[r][c] = append(funcs[r][c], f)
-				}
-			}
-		}
+	service, err := p.mapServiceID(w.serviceID())
+	if err != nil {
+		return nil, err
 	}
 
-	return funcs
-}
+	name, err := mapServiceName(w.serviceID())
+	if err != nil {
+		return nil, err
+	}
 
-// getConcurentRenderers and fetch information via different ways depending on Widget (API / SSH / ...)
-// A function to display the widget will be send to a channel.
-// One channel per widget to keep the widget order in a slice.
-func getChannelRenderers(s service, name string, w Widget, tui *Tui, c chan<- func() error) {
-	if s == nil {
-		c <- DisplayError(tui, errors.Errorf("can't use widget %s without service %s.", w.Name, name))
-	} else {
-		f, err := s.CreateWidgets(w, tui)
-		if err != nil {
-			c <- DisplayError(tui, errors.Errorf("%s / %s: %s", name, w.Name, err.Error()))
-		} else {
-			c <- f
-		}
+	if service == nil {
+		return nil, errors.Errorf("can't use widget %s without service %s.", w.Name, name)
 	}
-	close(c)
+
+	timeout := p.timeout
+	if w.Timeout > 0 {
+		timeout = time.Duration(w.Timeout) * time.Second
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	f, err := service.CreateWidgets(ctx, w.withoutInstance(), p.tui)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.Errorf("%s / %s: no data after %s", name, w.Name, timeout)
+	}
+	if err != nil {
+		return nil, errors.Errorf("%s / %s: %s", name, w.Name, err.Error())
+	}
+
+	return f, nil
 }
 
-// Create all the widgets and populate them with data.
-// Return slice of widgets
-func (p *project) CreateNonConcWidgets() [][][]func() error {
+// Render the title of the project and the layout of the widgets, each of them in its own cell of the grid.
+// The last data of each widget are displayed in its cell till they're fetched again, or a placeholder the first time.
+func (p *project) Render() {
 	// TODO: use display.box instead of this shortcut
 	err := p.addTitle(p.tui)
 	if err != nil {
 		err = errors.Wrapf(err, "can't add project title %s", p.name)
-		DisplayError(p.tui, err)()
+		p.tui.AddCell(DisplayError(p.tui, err))
 	}
 
-	funcs := make([][][]func() error, len(p.widgets))
-
-	for ir, row := range p.widgets {
-		for ic, col := range row {
-			funcs[ir] = append(funcs[ir], []func() error{})
-			for _, w := range col {
+	p.cells = make([][][]int, len(p.widgets))
+	for r, row := range p.widgets {
+		for c, col := range row {
+			p.cells[r] = append(p.cells[r], []int{})
+			for i, w := range col {
 				w = p.addDefaultTheme(w)
+				cell := p.tui.AddWidgetCell(p.widgetKey(r, c, i), DisplayLoading(p.tui, w))
+				p.cells[r][c] = append(p.cells[r][c], cell)
 
-				service, err := p.mapServiceID(w.serviceID())
-				if err != nil {
-					funcs[ir][ic] = append(funcs[ir][ic], DisplayError(p.tui, err))
-					continue
-				}
-
-				serviceName, err := mapServiceName(w.serviceID())
-				if err != nil {
-					funcs[ir][ic] = append(funcs[ir][ic], DisplayError(p.tui, err))
-					continue
+				// An unknown service is displayed as an error once the widget is fetched.
+				name, _ := mapServiceName(w.serviceID())
+				p.tui.AddWidgetInfo(cell, p.widgetKey(r, c, i), w.Name, name, w.Options)
+			}
+			if len(col) > 0 {
+				if err := p.tui.AddCol(p.sizes[r][c]); err != nil {
+					p.tui.AddCell(DisplayError(p.tui, err))
 				}
-
-				funcs[ir][ic] = append(funcs[ir][ic], getFuncRenderers(service, serviceName, w, p.tui))
 			}
 		}
+		p.tui.AddRow()
 	}
-
-	return funcs
+	p.tui.Render()
 }
 
-func getFuncRenderers(s service, name string, w Widget, tui *Tui) (f func() error) {
-	if s == nil {
-		f = DisplayError(tui, errors.Errorf(
-			"Configuration error - you can't use the widget %s without the service %s.",
-			w.Name,
-			name,
-		))
+// draw a widget in its cell, if the project is rendered and the context is not done.
+func (p *project) draw(ctx context.Context, cell int, key string, f func() error, err error) {
+	if cell == 0 || ctx.Err() != nil {
+		return
 	}
 
-	f, err := s.CreateWidgets(w, tui)
-	if err != nil {
-		f = DisplayError(tui, err)
+	p.tui.RedrawWidget(cell, key, f, err)
+}
+
+// cell where the widget is drawn. Return 0 (no cell) if the project is not rendered.
+func (p *project) cell(r, c, i int) int {
+	if len(p.cells) <= r || len(p.cells[r]) <= c || len(p.cells[r][c]) <= i {
+		return 0
 	}
 
-	return f
+	return p.cells[r][c][i]
+}
+
+// widgetKey identify a widget of the project, even after reloading the dashboard.
+func (p *project) widgetKey(r, c, i int) string {
+	return fmt.Sprintf("%s/%d/%d/%d/%s", p.name, r, c, i, p.widgets[r][c][i].Name)
 }
 
-func (p *project) Render(funcs [][][]func() error) {
+// WidgetKeys of every widget of the project, rendered or not.
+func (p *project) WidgetKeys() []string {
+	keys := []string{}
 	for r, row := range p.widgets {
 		for c, col := range row {
-			for _, f := range funcs[r][c] {
-				err := f()
-				if err != nil {
-					DisplayError(p.tui, err)()
-				}
+			for i := range col {
+				keys = append(keys, p.widgetKey(r, c, i))
 			}
-			if len(col) > 0 {
-				if err := p.tui.AddCol(p.sizes[r][c]); err != nil {
-					DisplayError(p.tui, err)()
-				}
+		}
+	}
+
+	return keys
+}
+
+// ScheduleRefresh of the widgets. The widgets having their own refresh interval are refreshed automatically,
+// the others with Scheduler.RefreshDefault, at the refresh interval of the dashboard. The project needs to be rendered first.
+func (p *project) ScheduleRefresh(s *Scheduler) {
+	for r, row := range p.cells {
+		for c, col := range row {
+			for i, cell := range col {
+				w, cell := p.widgets[r][c][i], cell
+				s.Add(cell, p.widgetKey(r, c, i), w.serviceID(), time.Duration(w.Refresh)*time.Second, func(ctx context.Context, force bool) (func() error, error) {
+					return p.refresh(ctx, cell, w, force)
+				})
 			}
 		}
-		p.tui.AddRow()
-		p.tui.Render()
 	}
 }
 
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_addDefaultTheme(t *testing.T) {
@@ -138,3 +140,109 @@ func Test_addDefaultTheme(t *testing.T) {
 		})
 	}
 }
+
+func Test_cell(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected int
+		cells    [][][]int
+		row      int
+		col      int
+		index    int
+	}{
+		{
+			name:     "project rendered",
+			expected: 5,
+			cells:    [][][]int{{{1, 2}, {3}}, {{4, 5}}},
+			row:      1,
+			col:      0,
+			index:    1,
+		},
+		{
+			name:     "project not rendered",
+			expected: 0,
+			cells:    nil,
+			row:      1,
+			col:      0,
+			index:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := project{cells: tc.cells}
+			actual := p.cell(tc.row, tc.col, tc.index)
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_WidgetKeys(t *testing.T) {
+	widgets := [][][]Widget{
+		{{{Name: "ga.box_total"}, {Name: "ga.box_users"}}, {{Name: "github.box_stars"}}},
+		{{{Name: "mon.box_availability"}}},
+	}
+	p := NewProject("project", nil, widgets, nil, nil, nil)
+
+	expected := []string{
+		"project/0/0/0/ga.box_total",
+		"project/0/0/1/ga.box_users",
+		"project/0/1/0/github.box_stars",
+		"project/1/0/0/mon.box_availability",
+	}
+	if actual := p.WidgetKeys(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+// blockingService wait till the context of the fetch is done.
+type blockingService struct {
+	err error
+}
+
+func (b *blockingService) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (func() error, error) {
+	<-ctx.Done()
+	b.err = ctx.Err()
+
+	return nil, b.err
+}
+
+func Test_fetchTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected error
+		timeout  time.Duration
+		widget   Widget
+	}{
+		{
+			name:     "timeout of the project",
+			expected: context.DeadlineExceeded,
+			timeout:  10 * time.Millisecond,
+			widget:   Widget{Name: "lh.box"},
+		},
+		{
+			name:     "timeout of the widget",
+			expected: context.DeadlineExceeded,
+			widget:   Widget{Name: "lh.box", Timeout: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &blockingService{}
+			p := project{
+				services: map[string]service{"lh": s},
+				timeout:  tc.timeout,
+			}
+
+			p.fetch(context.Background(), tc.widget)
+
+			if s.err != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, s.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+)
+
+// Scheduler fetch the data of the widgets with a limited number of workers, and refresh the widgets which are due
+// without rebuilding the rest of the dashboard. It stops when its context is done.
+//
+// The widgets fetched together (with Go, or refreshed at the same tick) share the results of their identical queries.
+type Scheduler struct {
+	ctx context.Context
+	// cycle is the context of the widgets fetched with Go.
+	cycle context.Context
+	tui   *Tui
+	tick  time.Duration
+	mu    sync.Mutex
+	jobs  []*job
+	// paused is true if the widgets are not refreshed automatically anymore.
+	paused bool
+
+	// workers limit the number of fetches running at the same time. Unlimited if nil.
+	workers chan struct{}
+	// limits of fetches running at the same time per service ID, or per service type for every instance of a service.
+	limits map[string]int
+	// services limit the fetches per service, indexed by the keys of the limits.
+	services map[string]chan struct{}
+}
+
+// job fetch the data of a widget and redraw it in its cell.
+type job struct {
+	cell      int
+	key       string
+	serviceID string
+	interval  time.Duration
+	next      time.Time
+	running   bool
+	// fetch the data of the widget. If force is true, the data are not taken from the cache.
+	fetch func(ctx context.Context, force bool) (func() error, error)
+}
+
+// NewScheduler checking every second if some widgets need to be refreshed, till the context is done.
+// The fetches running at the same time are limited to the number of workers (unlimited if 0),
+// and to the limits per service (for example "ga": 2), if any.
+func NewScheduler(ctx context.Context, tui *Tui, workers int, limits map[string]int) *Scheduler {
+	s := &Scheduler{
+		ctx:      ctx,
+		cycle:    platform.WithCoalescer(ctx),
+		tui:      tui,
+		tick:     time.Second,
+		limits:   map[string]int{},
+		services: map[string]chan struct{}{},
+	}
+
+	if workers > 0 {
+		s.workers = make(chan struct{}, workers)
+	}
+
+	for k, v := range limits {
+		s.limits[strings.ToLower(k)] = v
+	}
+
+	return s
+}
+
+// Go run the function f in the background as soon as a worker is available for the service.
+// The function is not run if the context of the scheduler is done before.
+func (s *Scheduler) Go(serviceID string, f func(ctx context.Context)) {
+	s.goWith(s.cycle, serviceID, f)
+}
+
+func (s *Scheduler) goWith(ctx context.Context, serviceID string, f func(ctx context.Context)) {
+	go func() {
+		release, ok := s.acquire(serviceID)
+		if !ok {
+			return
+		}
+		defer release()
+
+		f(ctx)
+	}()
+}
+
+// acquire a worker for the service, waiting for the one available.
+// Return false if the context of the scheduler is done before.
+func (s *Scheduler) acquire(serviceID string) (release func(), ok bool) {
+	// Waiting for the service first doesn't block a worker which could fetch for another service.
+	service := s.serviceLimit(serviceID)
+	if service != nil {
+		select {
+		case service <- struct{}{}:
+		case <-s.ctx.Done():
+			return nil, false
+		}
+	}
+
+	if s.workers != nil {
+		select {
+		case s.workers <- struct{}{}:
+		case <-s.ctx.Done():
+			if service != nil {
+				<-service
+			}
+			return nil, false
+		}
+	}
+
+	return func() {
+		if s.workers != nil {
+			<-s.workers
+		}
+		if service != nil {
+			<-service
+		}
+	}, true
+}
+
+// serviceLimit return the semaphore limiting the fetches of a service, nil if there is no limit.
+// The limit of a service instance (for example "rh:web1") has priority over the limit of the service itself ("rh").
+func (s *Scheduler) serviceLimit(serviceID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := serviceID
+	limit, ok := s.limits[key]
+	if !ok {
+		key, _ = splitInstance(serviceID)
+		limit, ok = s.limits[key]
+	}
+
+	if !ok || limit <= 0 {
+		return nil
+	}
+
+	if _, ok := s.services[key]; !ok {
+		s.services[key] = make(chan struct{}, limit)
+	}
+
+	return s.services[key]
+}
+
+// Add a widget to refresh at a given interval, drawn in the cell with the ID given.
+// If the interval is 0, the widget is only refreshed with RefreshDefault, or on demand.
+func (s *Scheduler) Add(
+	cell int,
+	key string,
+	serviceID string,
+	interval time.Duration,
+	fetch func(ctx context.Context, force bool) (func() error, error),
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &job{
+		cell:      cell,
+		key:       key,
+		serviceID: serviceID,
+		interval:  interval,
+		next:      time.Now().Add(interval),
+		fetch:     fetch,
+	})
+}
+
+// Start to refresh the widgets in the background.
+func (s *Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				cycle := platform.WithCoalescer(s.ctx)
+				for _, j := range s.due(now) {
+					j := j
+					s.goWith(cycle, j.serviceID, func(ctx context.Context) {
+						s.run(ctx, j, false)
+					})
+				}
+			}
+		}
+	}()
+}
+
+// Pause the automatic refresh of the widgets, or resume it.
+func (s *Scheduler) Pause(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = paused
+}
+
+// Refresh the widget drawn in a cell now, without using the cache, even if the automatic refresh is paused.
+// Nothing happens if the widget is already refreshing.
+func (s *Scheduler) Refresh(cell int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.cell != cell || j.running {
+			continue
+		}
+		j.running = true
+		j := j
+		s.goWith(platform.WithCoalescer(s.ctx), j.serviceID, func(ctx context.Context) {
+			s.run(ctx, j, true)
+		})
+	}
+}
+
+// RefreshDefault refresh the widgets which don't have their own refresh interval, at the refresh interval of the
+// whole dashboard. Nothing happens if the automatic refresh is paused.
+func (s *Scheduler) RefreshDefault() {
+	cycle := platform.WithCoalescer(s.ctx)
+	for _, j := range s.defaults() {
+		j := j
+		s.goWith(cycle, j.serviceID, func(ctx context.Context) {
+			s.run(ctx, j, false)
+		})
+	}
+}
+
+// defaults return the jobs without refresh interval which are not running, and mark them as running.
+func (s *Scheduler) defaults() []*job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := []*job{}
+	if s.paused {
+		return jobs
+	}
+
+	for _, j := range s.jobs {
+		if j.running || j.interval > 0 {
+			continue
+		}
+		j.running = true
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
+
+// due return the jobs which need to run, and mark them as running.
+func (s *Scheduler) due(now time.Time) []*job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := []*job{}
+	if s.paused {
+		return jobs
+	}
+
+	for _, j := range s.jobs {
+		if j.running || j.interval <= 0 || now.Before(j.next) {
+			continue
+		}
+		j.running = true
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job, force bool) {
+	f, err := j.fetch(ctx, force)
+
+	// The data fetched after the context is done are not drawn.
+	if ctx.Err() == nil {
+		s.tui.RedrawWidget(j.cell, j.key, f, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.running = false
+	j.next = time.Now().Add(j.interval)
+}
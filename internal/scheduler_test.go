@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_due(t *testing.T) {
+	now := time.Date(2021, time.May, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		expected []int
+		jobs     []*job
+		paused   bool
+	}{
+		{
+			name:     "no job",
+			expected: []int{},
+			jobs:     []*job{},
+		},
+		{
+			name:     "jobs due and not due",
+			expected: []int{1, 3},
+			jobs: []*job{
+				{cell: 1, interval: time.Minute, next: now.Add(-time.Second)},
+				{cell: 2, interval: time.Minute, next: now.Add(time.Second)},
+				{cell: 3, interval: time.Minute, next: now},
+			},
+		},
+		{
+			name:     "job due but already running",
+			expected: []int{2},
+			jobs: []*job{
+				{cell: 1, interval: time.Minute, next: now.Add(-time.Second), running: true},
+				{cell: 2, interval: time.Minute, next: now.Add(-time.Second)},
+			},
+		},
+		{
+			name:     "job only refreshed on demand",
+			expected: []int{2},
+			jobs: []*job{
+				{cell: 1, next: now.Add(-time.Second)},
+				{cell: 2, interval: time.Minute, next: now.Add(-time.Second)},
+			},
+		},
+		{
+			name:     "refresh paused",
+			expected: []int{},
+			jobs: []*job{
+				{cell: 1, interval: time.Minute, next: now.Add(-time.Second)},
+			},
+			paused: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Scheduler{jobs: tc.jobs, paused: tc.paused}
+			due := s.due(now)
+
+			if len(due) != len(tc.expected) {
+				t.Fatalf("Expected %d jobs, actual %d", len(tc.expected), len(due))
+			}
+
+			for k, j := range due {
+				if j.cell != tc.expected[k] {
+					t.Errorf("Expected cell %v, actual %v", tc.expected[k], j.cell)
+				}
+				if !j.running {
+					t.Errorf("Expected job of cell %v to be running", j.cell)
+				}
+			}
+		})
+	}
+}
+
+func Test_defaults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected []int
+		jobs     []*job
+		paused   bool
+	}{
+		{
+			name:     "jobs with and without refresh interval",
+			expected: []int{1, 3},
+			jobs: []*job{
+				{cell: 1},
+				{cell: 2, interval: time.Hour},
+				{cell: 3},
+			},
+		},
+		{
+			name:     "job already running",
+			expected: []int{2},
+			jobs: []*job{
+				{cell: 1, running: true},
+				{cell: 2},
+			},
+		},
+		{
+			name:     "refresh paused",
+			expected: []int{},
+			jobs: []*job{
+				{cell: 1},
+			},
+			paused: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Scheduler{jobs: tc.jobs, paused: tc.paused}
+			jobs := s.defaults()
+
+			if len(jobs) != len(tc.expected) {
+				t.Fatalf("Expected %d jobs, actual %d", len(tc.expected), len(jobs))
+			}
+
+			for k, j := range jobs {
+				if j.cell != tc.expected[k] {
+					t.Errorf("Expected cell %v, actual %v", tc.expected[k], j.cell)
+				}
+				if !j.running {
+					t.Errorf("Expected job of cell %v to be running", j.cell)
+				}
+			}
+		})
+	}
+}
+
+func Test_serviceLimit(t *testing.T) {
+	s := NewScheduler(context.Background(), nil, 0, map[string]int{"ga": 2, "RH:web1": 1})
+
+	testCases := []struct {
+		name      string
+		expected  int
+		serviceID string
+	}{
+		{
+			name:      "limit of the service",
+			expected:  2,
+			serviceID: "ga",
+		},
+		{
+			name:      "limit of the service instance",
+			expected:  1,
+			serviceID: "rh:web1",
+		},
+		{
+			name:      "no limit",
+			expected:  0,
+			serviceID: "rh:web2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := cap(s.serviceLimit(tc.serviceID))
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Go(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected int32
+		workers  int
+		limits   map[string]int
+	}{
+		{
+			name:     "limited by the workers",
+			expected: 2,
+			workers:  2,
+		},
+		{
+			name:     "limited by the service",
+			expected: 1,
+			workers:  4,
+			limits:   map[string]int{"ga": 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewScheduler(context.Background(), nil, tc.workers, tc.limits)
+
+			var running, maxRunning int32
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				s.Go("ga", func(ctx context.Context) {
+					defer wg.Done()
+					r := atomic.AddInt32(&running, 1)
+					for {
+						m := atomic.LoadInt32(&maxRunning)
+						if r <= m || atomic.CompareAndSwapInt32(&maxRunning, m, r) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+				})
+			}
+			wg.Wait()
+
+			if maxRunning != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, maxRunning)
+			}
+		})
+	}
+}
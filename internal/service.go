@@ -0,0 +1,301 @@
+package internal
+
+// Registry of every service available in a dashboard.
+// A service registers itself once (in the init function of its widget file) with its ID, its name,
+// the options it accepts in the "services" section of a project and its constructor.
+//
+// A service can have multiple named instances in the same project, for example "remote_host:web1" and "remote_host:db1".
+// The widgets refer to these instances with the service ID followed by the name of the instance (for example "rh:web1.box_load").
+//
+// The services are kept in a ServiceStore across the reloads of the dashboard, as long as their configuration doesn't change.
+// A service implementing io.Closer is closed when it's not used anymore.
+
+import (
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type service interface {
+	CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error)
+}
+
+// ServiceOption is an option of a service in the "services" section of a project.
+type ServiceOption struct {
+	Name string
+	// Env is the environment variable used when the option is empty.
+	Env string
+}
+
+// ServiceDefinition describes a service and how to create it.
+type ServiceDefinition struct {
+	// ID used as prefix of widget names, for example "ga" for "ga.bar_sessions".
+	ID string
+	// Name of the service displayed to the user.
+	Name string
+	// ConfigKey of the service in the "services" section of a project.
+	// A service without ConfigKey doesn't need any configuration and is always created.
+	ConfigKey string
+	Options   []ServiceOption
+	// Widgets of the service, with the options they accept.
+	Widgets []WidgetSchema
+	New     func(config map[string]string) (service, error)
+	// Doctor checks the credentials and the connectivity of the service, for the command "doctor".
+	// The services without doctor are only created.
+	Doctor func(ctx context.Context, config map[string]string) []Diagnosis
+}
+
+// widget of the service, with its name without the ID of the service (for example "bar_sessions").
+func (d ServiceDefinition) widget(name string) (WidgetSchema, bool) {
+	for _, w := range d.Widgets {
+		if w.Name == name {
+			return w, true
+		}
+	}
+
+	return WidgetSchema{}, false
+}
+
+// instanceSeparator separates the service from the name of its instance.
+const instanceSeparator = ":"
+
+var registry = map[string]ServiceDefinition{}
+
+// RegisterService to make it available in every dashboard.
+// Panic if a service with the same ID is already registered.
+func RegisterService(def ServiceDefinition) {
+	if def.ID == "" || def.New == nil {
+		panic("a service needs at least an ID and a constructor")
+	}
+
+	if _, ok := registry[def.ID]; ok {
+		panic("service " + def.ID + " already registered")
+	}
+
+	registry[def.ID] = def
+}
+
+// ServiceDefinitions return every registered service, ordered by ID.
+func ServiceDefinitions() []ServiceDefinition {
+	defs := make([]ServiceDefinition, 0, len(registry))
+	for _, v := range registry {
+		defs = append(defs, v)
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].ID < defs[j].ID
+	})
+
+	return defs
+}
+
+func lookupService(serviceID string) (ServiceDefinition, error) {
+	if def, ok := registry[serviceID]; ok {
+		return def, nil
+	}
+
+	return ServiceDefinition{}, errors.Errorf("Impossible to find the service with ID %s", serviceID)
+}
+
+// NewServices create every registered service, depending on the "services" section of a project.
+// Return the services created, indexed by ID (with the instance name if any), and the errors of the services which couldn't be created.
+func NewServices(config map[string]map[string]string) (map[string]service, []error) {
+	return createServices(config, func(def ServiceDefinition, conf map[string]string) (service, error) {
+		return def.New(conf)
+	})
+}
+
+// createServices depending on the "services" section of a project, with the function create.
+func createServices(
+	config map[string]map[string]string,
+	create func(def ServiceDefinition, conf map[string]string) (service, error),
+) (map[string]service, []error) {
+	services := map[string]service{}
+	errs := []error{}
+
+	eachService(config, func(def ServiceDefinition, instance string, conf map[string]string) {
+		s, err := create(def, conf)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "can't create service %s", serviceName(def, instance)))
+			return
+		}
+
+		id := def.ID
+		if instance != "" {
+			id += instanceSeparator + instance
+		}
+		services[id] = s
+	})
+
+	return services, errs
+}
+
+// eachService of the "services" section of a project, with the name of its instance and its configuration.
+// The services without configuration key are always there.
+func eachService(config map[string]map[string]string, f func(def ServiceDefinition, instance string, conf map[string]string)) {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, def := range ServiceDefinitions() {
+		if def.ConfigKey == "" {
+			f(def, "", map[string]string{})
+			continue
+		}
+
+		for _, k := range keys {
+			configKey, instance := splitInstance(strings.ToLower(k))
+			if configKey != def.ConfigKey || len(config[k]) == 0 {
+				continue
+			}
+			f(def, instance, def.config(config[k]))
+		}
+	}
+}
+
+// splitInstance split a service key into the service itself and the name of its instance.
+func splitInstance(key string) (string, string) {
+	s := strings.SplitN(key, instanceSeparator, 2)
+	if len(s) == 1 {
+		return s[0], ""
+	}
+
+	return s[0], s[1]
+}
+
+func serviceName(def ServiceDefinition, instance string) string {
+	if instance == "" {
+		return def.Name
+	}
+
+	return def.Name + " (" + instance + ")"
+}
+
+// config of the service with the environment variables as fallback for empty options.
+func (d ServiceDefinition) config(conf map[string]string) map[string]string {
+	c := make(map[string]string, len(conf))
+	for k, v := range conf {
+		c[k] = v
+	}
+
+	for _, o := range d.Options {
+		if c[o.Name] == "" && o.Env != "" {
+			c[o.Name] = os.Getenv(o.Env)
+		}
+	}
+
+	return c
+}
+
+// ServiceStore create the services of the dashboard and keep them across reloads, as long as their configuration doesn't change.
+type ServiceStore struct {
+	mu       sync.Mutex
+	services map[string]service
+	// used since the last release, indexed by the same keys as the services.
+	used map[string]bool
+}
+
+// NewServiceStore without any service.
+func NewServiceStore() *ServiceStore {
+	return &ServiceStore{
+		services: map[string]service{},
+		used:     map[string]bool{},
+	}
+}
+
+// Services for the "services" section of a project, like NewServices.
+// A service already created with the same configuration is reused; the services which couldn't be created are tried again next time.
+func (s *ServiceStore) Services(config map[string]map[string]string) (map[string]service, []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return createServices(config, func(def ServiceDefinition, conf map[string]string) (service, error) {
+		key := configID(def.ID, conf)
+		s.used[key] = true
+		if sv, ok := s.services[key]; ok {
+			return sv, nil
+		}
+
+		sv, err := def.New(conf)
+		if err != nil {
+			return nil, err
+		}
+		s.services[key] = sv
+
+		return sv, nil
+	})
+}
+
+// Keep the services of the "services" section of a project at the next release, without creating them.
+// For example, the services of the projects of the pages not displayed.
+func (s *ServiceStore) Keep(config map[string]map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eachService(config, func(def ServiceDefinition, instance string, conf map[string]string) {
+		s.used[configID(def.ID, conf)] = true
+	})
+}
+
+// Release the services which were not used or kept since the last release.
+// It should be called each time every project of the dashboard got its services, or kept them.
+func (s *ServiceStore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, sv := range s.services {
+		if !s.used[k] {
+			closeService(sv)
+			delete(s.services, k)
+		}
+	}
+	s.used = map[string]bool{}
+}
+
+// Close every service of the store.
+func (s *ServiceStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, sv := range s.services {
+		closeService(sv)
+		delete(s.services, k)
+	}
+	s.used = map[string]bool{}
+}
+
+func closeService(s service) {
+	if c, ok := s.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// configID identify a service by its ID and its configuration, without the options ignored.
+func configID(serviceID string, conf map[string]string, ignored ...string) string {
+	keys := make([]string, 0, len(conf))
+	for k := range conf {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	skip := map[string]bool{}
+	for _, k := range ignored {
+		skip[k] = true
+	}
+
+	id := serviceID
+	for _, k := range keys {
+		if !skip[k] {
+			id += "|" + k + "=" + conf[k]
+		}
+	}
+
+	return id
+}
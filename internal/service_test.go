@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_NewServices(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected []string
+		config   map[string]map[string]string
+	}{
+		{
+			name:     "no service configured",
+			expected: []string{"display", "lh"},
+			config:   map[string]map[string]string{},
+		},
+		{
+			name:     "services configured",
+			expected: []string{"display", "feedly", "git", "lh"},
+			config: map[string]map[string]string{
+				"git":    {"path": "."},
+				"feedly": {"address": "https://thevaluable.dev"},
+			},
+		},
+		{
+			name:     "multiple instances of the same service",
+			expected: []string{"display", "git", "git:api", "git:web", "lh"},
+			config: map[string]map[string]string{
+				"git":     {"path": "."},
+				"git:api": {"path": "api"},
+				"git:Web": {"path": "web"},
+			},
+		},
+		{
+			name:     "empty service and unknown service",
+			expected: []string{"display", "lh"},
+			config: map[string]map[string]string{
+				"git":     {},
+				"unknown": {"address": "https://thevaluable.dev"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			services, errs := NewServices(tc.config)
+			if len(errs) > 0 {
+				t.Errorf("Expected no error, actual %v", errs)
+			}
+
+			actual := []string{}
+			for k := range services {
+				actual = append(actual, k)
+			}
+			sort.Strings(actual)
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_serviceConfig(t *testing.T) {
+	os.Setenv("DEVDASH_TEST_TOKEN", "env_token")
+	defer os.Unsetenv("DEVDASH_TEST_TOKEN")
+
+	def := ServiceDefinition{
+		ID: "test",
+		Options: []ServiceOption{
+			{Name: "token", Env: "DEVDASH_TEST_TOKEN"},
+			{Name: "owner"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		expected map[string]string
+		config   map[string]string
+	}{
+		{
+			name:     "fallback on environment variable",
+			expected: map[string]string{"token": "env_token", "owner": "Phantas0s"},
+			config:   map[string]string{"owner": "Phantas0s"},
+		},
+		{
+			name:     "option from the config",
+			expected: map[string]string{"token": "config_token", "owner": ""},
+			config:   map[string]string{"token": "config_token"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := def.config(tc.config)
+
+			if actual["token"] != tc.expected["token"] || actual["owner"] != tc.expected["owner"] {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_mapServiceName(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expected  string
+		serviceID string
+		wantErr   bool
+	}{
+		{
+			name:      "registered service",
+			expected:  "Google Analytics",
+			serviceID: "ga",
+		},
+		{
+			name:      "instance of a registered service",
+			expected:  "Remote Host (web1)",
+			serviceID: "rh:web1",
+		},
+		{
+			name:      "unknown service",
+			serviceID: "gitub",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := mapServiceName(tc.serviceID)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+type closerService struct {
+	closed bool
+}
+
+func (c *closerService) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (func() error, error) {
+	return nil, nil
+}
+
+func (c *closerService) Close() error {
+	c.closed = true
+	return nil
+}
+
+func Test_ServiceStore(t *testing.T) {
+	RegisterService(ServiceDefinition{
+		ID:        "closer",
+		ConfigKey: "closer",
+		New: func(map[string]string) (service, error) {
+			return &closerService{}, nil
+		},
+	})
+	defer delete(registry, "closer")
+
+	store := NewServiceStore()
+
+	first, _ := store.Services(map[string]map[string]string{"closer": {"address": "first"}})
+	store.Release()
+	reused, _ := store.Services(map[string]map[string]string{"closer": {"address": "first"}})
+	store.Release()
+
+	if first["closer"] != reused["closer"] {
+		t.Errorf("Expected the service to be reused")
+	}
+
+	changed, _ := store.Services(map[string]map[string]string{"closer": {"address": "second"}})
+	store.Release()
+
+	if first["closer"] == changed["closer"] {
+		t.Errorf("Expected a new service when the configuration change")
+	}
+
+	if !first["closer"].(*closerService).closed {
+		t.Errorf("Expected the previous service to be closed")
+	}
+
+	// The service of a page not displayed is kept.
+	store.Keep(map[string]map[string]string{"closer": {"address": "second"}})
+	store.Release()
+
+	if changed["closer"].(*closerService).closed {
+		t.Errorf("Expected the service kept not to be closed")
+	}
+
+	kept, _ := store.Services(map[string]map[string]string{"closer": {"address": "second"}})
+	store.Release()
+
+	if changed["closer"] != kept["closer"] {
+		t.Errorf("Expected the service kept to be reused")
+	}
+
+	store.Close()
+	if !changed["closer"].(*closerService).closed {
+		t.Errorf("Expected every service to be closed")
+	}
+}
@@ -1,7 +1,7 @@
 package internal
 
 import (
-	"strconv"
+	"context"
 
 	"github.com/Phantas0s/devdash/internal/platform"
 	"github.com/pkg/errors"
@@ -11,9 +11,39 @@ const (
 	travisCITableBuilds = "travis.table_builds"
 )
 
+func init() {
+	RegisterService(ServiceDefinition{
+		ID:        "travis",
+		Name:      "Travis",
+		ConfigKey: "travis",
+		Options:   []ServiceOption{{Name: "token"}, {Name: optionCacheTTL}},
+		Widgets: []WidgetSchema{
+			widgetSchema(
+				travisCITableBuilds,
+				titleOption(" Travis CI builds "),
+				[]OptionSchema{
+					{Name: optionRepository, Type: typeString, Description: "Repository of the builds."},
+					{Name: optionOwner, Type: typeString, Description: "Owner of the repository."},
+					{Name: optionRowLimit, Type: typeInt, Default: "5", Description: "Maximum number of rows."},
+				},
+				tableOptions,
+				cacheOptions,
+			),
+		},
+		New: func(config map[string]string) (service, error) {
+			var err error
+			tc := NewTravisCIWidget(config["token"])
+			tc.cache, err = newServiceCache("travis", config)
+			return tc, err
+		},
+		Doctor: travisDoctor,
+	})
+}
+
 type travisCIWidget struct {
 	tui    *Tui
 	client *platform.TravisCI
+	cache  serviceCache
 }
 
 // NewTravisCIWidget with all information necessary to connect to the Github API.
@@ -24,12 +54,12 @@ func NewTravisCIWidget(token string) *travisCIWidget {
 	}
 }
 
-func (tc travisCIWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+func (tc travisCIWidget) CreateWidgets(ctx context.Context, widget Widget, tui *Tui) (f func() error, err error) {
 	tc.tui = tui
 
 	switch widget.Name {
 	case travisCITableBuilds:
-		f, err = tc.tableBuilds(widget)
+		f, err = tc.tableBuilds(ctx, widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service travis ci", widget.Name)
 	}
@@ -37,11 +67,8 @@ func (tc travisCIWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error,
 	return
 }
 
-func (tc travisCIWidget) tableBuilds(widget Widget) (f func() error, err error) {
-	title := " Travis CI builds "
-	if _, ok := widget.Options[optionTitle]; ok {
-		title = widget.Options[optionTitle]
-	}
+func (tc travisCIWidget) tableBuilds(ctx context.Context, widget Widget) (f func() error, err error) {
+	title := widget.option(optionTitle)
 
 	repo := ""
 	if _, ok := widget.Options[optionRepository]; ok {
@@ -53,22 +80,40 @@ func (tc travisCIWidget) tableBuilds(widget Widget) (f func() error, err error)
 		owner = widget.Options[optionOwner]
 	}
 
-	var limit int64 = 5
-	if _, ok := widget.Options[optionRowLimit]; ok {
-		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
-		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
-		}
+	limit, err := widget.intOption(optionRowLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	builds, err := tc.client.Builds(repo, owner, limit)
+	var builds linkedTable
+	err = tc.cache.fetch(ctx, widget, "builds", []interface{}{repo, owner, limit}, &builds, func() (err error) {
+		builds.Rows, builds.URLs, err = tc.client.Builds(ctx, repo, owner, limit)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	f = func() error {
-		return tc.tui.AddTable(builds, title, widget.Options)
+		return tc.tui.AddTableWithURLs(builds.Rows, builds.URLs, title, widget.Options)
 	}
 
 	return
 }
+
+// travisDoctor check the token of Travis CI, if any.
+func travisDoctor(ctx context.Context, config map[string]string) []Diagnosis {
+	d := diagnoses{}
+	if config["token"] == "" || config["token"] == "none" {
+		d.add("no token: only the public repositories are displayed", nil, "")
+		return d
+	}
+
+	d.add(
+		"token is valid",
+		NewTravisCIWidget(config["token"]).client.CheckToken(ctx),
+		"Create a new API token in the settings of Travis CI, and set the option token of the service.",
+	)
+
+	return d
+}
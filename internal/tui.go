@@ -2,11 +2,13 @@
 package internal
 
 import (
+	"fmt"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 const (
@@ -32,7 +34,7 @@ const (
 
 	optionBold = "bold"
 
-	optionMultiline = "false"
+	optionMultiline = "multiline"
 
 	optionFirstColor  = "first_color"
 	optionSecondColor = "second_color"
@@ -43,6 +45,9 @@ const (
 
 	optionHeight = "height"
 
+	optionAction        = "action"
+	optionActionCommand = "action_command"
+
 	optionBarGap   = "bar_gap"
 	optionBarWidth = "bar_width"
 	optionBarColor = "bar_color"
@@ -143,6 +148,8 @@ type drawer interface {
 		titleColor uint16,
 		bd uint16,
 		fg uint16,
+		height int,
+		actions []func() error,
 	)
 
 	Gauge(
@@ -156,10 +163,18 @@ type drawer interface {
 	)
 	AddCol(size int)
 	AddRow()
+	AddCell() int
+	DrawCell(id int, draw func() error) error
+	MarkStale(id int, label string)
+	StatusLine(text string, textColor uint16)
+	TabBar(tabs []string, current int)
+	Overlay(title string, text string)
 }
 
 type keyManager interface {
+	KAction(key string, action func())
 	KQuit(key string)
+	Mouse(click func(x, y, wheel int))
 	KHotReload(key string, c chan<- time.Time)
 	KEdit(
 		key string,
@@ -167,6 +182,16 @@ type keyManager interface {
 	)
 }
 
+// focuser move the focus between the widgets, to scroll them or display them on the whole terminal.
+type focuser interface {
+	Focus(offset int)
+	Focused() int
+	Scroll(lines int)
+	Zoom()
+	RowAction() func() error
+	Click(x, y, wheel int) int
+}
+
 type looper interface {
 	Loop()
 }
@@ -181,6 +206,7 @@ type aligner interface {
 
 type manager interface {
 	keyManager
+	focuser
 	renderer
 	drawer
 	looper
@@ -262,6 +288,188 @@ func (t *Tui) AddRow() {
 	t.instance.AddRow()
 }
 
+// AddCell to the current column of the grid and draw a widget into it.
+// Return the ID of the cell to be able to redraw it later.
+func (t *Tui) AddCell(draw func() error) int {
+	id := t.instance.AddCell()
+	t.drawCell(id, draw)
+
+	return id
+}
+
+// AddWidgetCell to the current column of the grid, for the widget with the key given.
+// The last widget drawn successfully with the same key is drawn again and marked as refreshing (or stale if it couldn't
+// be refreshed before), till the new data arrive. The placeholder is drawn if the widget was never drawn.
+func (t *Tui) AddWidgetCell(key string, placeholder func() error) int {
+	t.mu.Lock()
+	last, ok := t.lastDraws[key]
+	_, stale := t.staleBefore[key]
+	t.kept[key] = true
+	t.mu.Unlock()
+
+	if !ok {
+		return t.AddCell(placeholder)
+	}
+
+	id := t.instance.AddCell()
+	if t.drawCell(id, last.draw) {
+		label := " refreshing "
+		if stale {
+			label = fmt.Sprintf(" stale since %s ", last.at.Format("15:04"))
+		}
+		t.instance.MarkStale(id, label)
+	}
+
+	return id
+}
+
+// RedrawWidget in its cell, without rebuilding the rest of the grid.
+// The key identifies the widget across reloads of the dashboard.
+// If the data of the widget couldn't be fetched (err is not nil), the last widget drawn successfully is drawn again
+// and marked as stale, and the error is displayed in the status line. Without any previous widget, the error is drawn in the cell.
+func (t *Tui) RedrawWidget(id int, key string, draw func() error, err error) {
+	drawn := err == nil && t.drawCell(id, draw)
+	if drawn {
+		t.mu.Lock()
+		t.lastDraws[key] = lastDraw{draw: draw, at: time.Now()}
+		delete(t.staleErrors, key)
+		t.mu.Unlock()
+	}
+
+	if err != nil {
+		t.mu.Lock()
+		last, ok := t.lastDraws[key]
+		if ok {
+			t.staleErrors[key] = staleError{err: err, at: time.Now()}
+		}
+		t.mu.Unlock()
+
+		if ok && t.drawCell(id, last.draw) {
+			t.instance.MarkStale(id, fmt.Sprintf(" stale since %s ", last.at.Format("15:04")))
+		} else {
+			t.drawCell(id, DisplayError(t, err))
+		}
+	}
+
+	t.mu.Lock()
+	if drawn {
+		delete(t.failing, key)
+	} else {
+		t.failing[key] = true
+	}
+	t.mu.Unlock()
+
+	t.updateStatus()
+	t.instance.Align()
+	t.instance.Render()
+}
+
+// StatusBar of the dashboard, displayed at the bottom of the terminal.
+type StatusBar struct {
+	// Config file of the dashboard.
+	Config      string
+	LastRefresh time.Time
+	NextRefresh time.Time
+	// Paused is true if the dashboard is not refreshed automatically.
+	Paused bool
+}
+
+// SetStatusBar display the status bar, with the status of the stale widgets if any.
+func (t *Tui) SetStatusBar(bar StatusBar) {
+	t.mu.Lock()
+	t.bar = &bar
+	t.mu.Unlock()
+
+	t.updateStatus()
+	t.instance.Render()
+}
+
+// updateStatus line with the status bar if it's enabled, and the status of the stale widgets.
+func (t *Tui) updateStatus() {
+	text := t.status()
+	var color uint16 = red
+
+	t.mu.Lock()
+	if t.bar != nil {
+		bar := statusBarText(*t.bar, len(t.failing), time.Now())
+		if text == "" {
+			color = white
+			text = bar
+		} else {
+			text = bar + "|" + text
+		}
+	}
+	t.mu.Unlock()
+
+	t.instance.StatusLine(text, color)
+}
+
+// statusBarText with the state of the dashboard at a given time, and the number of widgets in error.
+func statusBarText(bar StatusBar, failing int, now time.Time) string {
+	next := "Auto refresh paused"
+	if !bar.Paused {
+		countdown := bar.NextRefresh.Sub(now).Round(time.Second)
+		if countdown < 0 {
+			countdown = 0
+		}
+		next = fmt.Sprintf("Next refresh in %s", countdown)
+	}
+
+	last := "never"
+	if !bar.LastRefresh.IsZero() {
+		last = bar.LastRefresh.Format("15:04:05")
+	}
+
+	return fmt.Sprintf(
+		" %s | Last refresh %s | %s | Widgets in error: %d ",
+		filepath.Base(bar.Config),
+		last,
+		next,
+		failing,
+	)
+}
+
+// drawCell and display the error in the cell if the widget can't be drawn.
+// Return false if the widget couldn't be drawn.
+func (t *Tui) drawCell(id int, draw func() error) bool {
+	if err := t.instance.DrawCell(id, draw); err != nil {
+		t.instance.DrawCell(id, DisplayError(t, err))
+		return false
+	}
+
+	return true
+}
+
+// status of the dashboard, with the most recent error of the stale widgets.
+func (t *Tui) status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	action := ""
+	if t.actionError != nil {
+		action = fmt.Sprintf(" Action failed: %s ", t.actionError.Error())
+	}
+
+	if len(t.staleErrors) == 0 {
+		return action
+	}
+
+	errs := make([]staleError, 0, len(t.staleErrors))
+	for _, v := range t.staleErrors {
+		errs = append(errs, v)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].at.After(errs[j].at)
+	})
+
+	stale := fmt.Sprintf(" Stale widgets: %d | %s ", len(errs), errs[0].err.Error())
+	if action != "" {
+		return action + "|" + stale
+	}
+
+	return stale
+}
+
 // Render the TUI.
 func (t *Tui) Render() {
 	t.instance.Render()
@@ -274,12 +482,67 @@ func (t *Tui) Close() {
 
 func NewTUI(instance manager) *Tui {
 	return &Tui{
-		instance: instance,
+		instance:    instance,
+		lastDraws:   map[string]lastDraw{},
+		kept:        map[string]bool{},
+		staleErrors: map[string]staleError{},
+		widgets:     map[int]widgetInfo{},
+		failing:     map[string]bool{},
 	}
 }
 
 type Tui struct {
 	instance manager
+
+	mu sync.Mutex
+	// lastDraws of the widgets drawn successfully, indexed by widget key. They're kept across reloads.
+	lastDraws map[string]lastDraw
+	// kept widgets since the last release of the last draws, indexed by widget key.
+	kept map[string]bool
+	// staleErrors of the widgets which couldn't be refreshed, indexed by widget key.
+	staleErrors map[string]staleError
+	// staleBefore the reload of the dashboard. Only the widgets still displayed stay stale.
+	staleBefore map[string]staleError
+	// bindings of the keys, in the order they were added.
+	bindings []binding
+	// widgets of the dashboard, indexed by cell ID.
+	widgets map[int]widgetInfo
+	// help is true if the help is displayed.
+	help bool
+	// failing widgets which couldn't be fetched or drawn, indexed by widget key.
+	failing map[string]bool
+	// failingBefore the reload of the dashboard. Only the widgets still displayed keep failing.
+	failingBefore map[string]bool
+	// bar displayed at the bottom of the terminal. Not displayed if nil.
+	bar *StatusBar
+	// actionError of the last action run from a row, if it failed.
+	actionError error
+	// configError of the configuration, if it couldn't be loaded.
+	configError error
+}
+
+type binding struct {
+	key         string
+	description string
+}
+
+// widgetInfo displayed in the help when the widget is focused.
+type widgetInfo struct {
+	name      string
+	service   string
+	options   map[string]string
+	fetchedAt time.Time
+	duration  time.Duration
+}
+
+type lastDraw struct {
+	draw func() error
+	at   time.Time
+}
+
+type staleError struct {
+	err error
+	at  time.Time
 }
 
 // Map the size of each column if t-shirt size is provided (XXS to XL).
@@ -299,22 +562,16 @@ func MapSize(size string) (int, error) {
 
 // AddProjectTitle to the TUI.
 func (t *Tui) AddProjectTitle(title string, options map[string]string) (err error) {
-	size := "XXL"
-	if _, ok := options[optionSize]; ok {
-		size = options[optionSize]
-	}
+	size := optionValue(projectTitleOptions, options, optionSize)
 
-	bold := true
-	if _, ok := options[optionBold]; ok {
-		bold, err = strconv.ParseBool(options[optionBold])
-		if err != nil {
-			return errors.Wrapf(err, "can't convert %s to bool - please verify your configuration (correct values: true or false)", options[optionBold])
-		}
+	bold, err := boolValue(projectTitleOptions, options, optionBold)
+	if err != nil {
+		return err
 	}
 
-	var height int64 = 3
-	if _, ok := options[optionHeight]; ok {
-		height, _ = strconv.ParseInt(options[optionHeight], 0, 0)
+	height, err := intValue(projectTitleOptions, options, optionHeight)
+	if err != nil {
+		return err
 	}
 
 	s, err := MapSize(size)
@@ -341,29 +598,19 @@ func (t *Tui) AddTextBox(
 	title string,
 	options map[string]string,
 ) (err error) {
-
-	var height int64 = 3
-	if _, ok := options[optionHeight]; ok {
-		height, _ = strconv.ParseInt(options[optionHeight], 0, 0)
+	height, err := intValue(textBoxOptions, options, optionHeight)
+	if err != nil {
+		return err
 	}
 
-	multiline := false
-	if _, ok := options[optionMultiline]; ok {
-		multiline, err = strconv.ParseBool(options[optionMultiline])
-		if err != nil {
-			return errors.Wrapf(
-				err, "can't convert %s to bool - please verify your configuration (correct values: 'true' or 'false')",
-				options[optionMultiline],
-			)
-		}
+	multiline, err := boolValue(textBoxOptions, options, optionMultiline)
+	if err != nil {
+		return err
 	}
 
-	bold := false
-	if _, ok := options[optionBold]; ok {
-		bold, err = strconv.ParseBool(options[optionBold])
-		if err != nil {
-			return errors.Wrapf(err, "can't convert %s to bool - please verify your configuration (correct values: true or false)", options[optionBold])
-		}
+	bold, err := boolValue(textBoxOptions, options, optionBold)
+	if err != nil {
+		return err
 	}
 
 	ce := createColoredElements(options)
@@ -386,10 +633,9 @@ func (t *Tui) AddGauge(
 	title string,
 	options map[string]string,
 ) (err error) {
-
-	var height int64 = 3
-	if _, ok := options[optionHeight]; ok {
-		height, _ = strconv.ParseInt(options[optionHeight], 0, 0)
+	height, err := intValue(gaugeOptions, options, optionHeight)
+	if err != nil {
+		return err
 	}
 
 	ce := createColoredElements(options)
@@ -413,28 +659,19 @@ func (t *Tui) AddBarChart(
 	title string,
 	options map[string]string,
 ) (err error) {
-	var height int64 = 10
-	if _, ok := options[optionHeight]; ok {
-		height, err = strconv.ParseInt(options[optionHeight], 0, 0)
-		if err != nil {
-			return err
-		}
+	height, err := intValue(barChartOptions, options, optionHeight)
+	if err != nil {
+		return err
 	}
 
-	var gap int64 = 0
-	if _, ok := options[optionBarGap]; ok {
-		gap, err = strconv.ParseInt(options[optionBarGap], 0, 0)
-		if err != nil {
-			return err
-		}
+	gap, err := intValue(barChartOptions, options, optionBarGap)
+	if err != nil {
+		return err
 	}
 
-	var barWidth int64 = 6
-	if _, ok := options[optionBarWidth]; ok {
-		barWidth, err = strconv.ParseInt(options[optionBarWidth], 0, 0)
-		if err != nil {
-			return err
-		}
+	barWidth, err := intValue(barChartOptions, options, optionBarWidth)
+	if err != nil {
+		return err
 	}
 
 	ce := createColoredElements(options)
@@ -464,28 +701,19 @@ func (t *Tui) AddStackedBarChart(
 	colors []uint16,
 	options map[string]string,
 ) (err error) {
-	var height int64 = 10
-	if _, ok := options[optionHeight]; ok {
-		height, err = strconv.ParseInt(options[optionHeight], 0, 0)
-		if err != nil {
-			return err
-		}
+	height, err := intValue(stackedBarChartOptions, options, optionHeight)
+	if err != nil {
+		return err
 	}
 
-	var gap int64 = 0
-	if _, ok := options[optionBarGap]; ok {
-		gap, err = strconv.ParseInt(options[optionBarGap], 0, 0)
-		if err != nil {
-			return err
-		}
+	gap, err := intValue(stackedBarChartOptions, options, optionBarGap)
+	if err != nil {
+		return err
 	}
 
-	var barWidth int64 = 6
-	if _, ok := options[optionBarWidth]; ok {
-		barWidth, err = strconv.ParseInt(options[optionBarWidth], 0, 0)
-		if err != nil {
-			return err
-		}
+	barWidth, err := intValue(stackedBarChartOptions, options, optionBarWidth)
+	if err != nil {
+		return err
 	}
 
 	ce := createColoredElements(options)
@@ -507,7 +735,24 @@ func (t *Tui) AddStackedBarChart(
 }
 
 // AddTable to the TUI, with a header and the dataset.
+// If no height is given, the table is as high as its rows.
 func (t *Tui) AddTable(data [][]string, title string, options map[string]string) error {
+	return t.AddTableWithURLs(data, nil, title, options)
+}
+
+// AddTableWithURLs to the TUI, each row of the dataset having the URL with the same index, if any.
+// The action of the selected row (opening its URL by default) is run with the action key.
+func (t *Tui) AddTableWithURLs(data [][]string, urls []string, title string, options map[string]string) (err error) {
+	actions, err := rowActions(data, urls, options)
+	if err != nil {
+		return err
+	}
+
+	height, err := intValue(tableOptions, options, optionHeight)
+	if err != nil {
+		return err
+	}
+
 	ce := createColoredElements(options)
 	t.instance.Table(
 		data,
@@ -515,17 +760,90 @@ func (t *Tui) AddTable(data [][]string, title string, options map[string]string)
 		ce.titleColor,
 		ce.borderColor,
 		ce.textColor,
+		int(height),
+		actions,
 	)
 
 	return nil
 }
 
+// AddTabBar with the names of the pages of the dashboard. The current page is highlighted.
+func (t *Tui) AddTabBar(tabs []string, current int) {
+	t.instance.TabBar(tabs, current)
+}
+
+// AddKAction run the action each time the key is pressed. The description is displayed in the help.
+func (t *Tui) AddKAction(key string, description string, action func()) {
+	t.addBinding(key, description)
+	t.instance.KAction(key, action)
+}
+
+// AddKHelp show or hide the help each time the key is pressed.
+func (t *Tui) AddKHelp(key string) {
+	t.AddKAction(key, "Show or hide this help", t.ToggleHelp)
+}
+
+// Focus the widget at some offset of the one currently focused. A negative offset focus the previous widgets.
+func (t *Tui) Focus(offset int) {
+	t.instance.Focus(offset)
+	t.updateOverlay()
+	t.instance.Render()
+}
+
+// Focused return the ID of the cell of the focused widget, or 0 if no widget is focused.
+func (t *Tui) Focused() int {
+	return t.instance.Focused()
+}
+
+// Scroll the focused widget by some lines. A negative number of lines scroll up.
+func (t *Tui) Scroll(lines int) {
+	t.instance.Scroll(lines)
+	t.instance.Render()
+}
+
+// Zoom the focused widget on the whole terminal, or come back to the whole dashboard.
+func (t *Tui) Zoom() {
+	t.instance.Zoom()
+	t.instance.Render()
+}
+
+// RunAction of the row selected in the focused widget, if any. The error of the action is displayed in the status line.
+func (t *Tui) RunAction() {
+	action := t.instance.RowAction()
+	if action == nil {
+		return
+	}
+	err := action()
+
+	t.mu.Lock()
+	t.actionError = err
+	t.mu.Unlock()
+
+	t.updateStatus()
+	t.instance.Render()
+}
+
+// EnableMouse to focus the widgets and scroll them by clicking on them or with the wheel, and to switch pages by
+// clicking on the tabs. The function tab is called with the index of the tab clicked.
+func (t *Tui) EnableMouse(tab func(index int)) {
+	t.instance.Mouse(func(x, y, wheel int) {
+		if index := t.instance.Click(x, y, wheel); index >= 0 {
+			tab(index)
+			return
+		}
+		t.updateOverlay()
+		t.instance.Render()
+	})
+}
+
 // Add keyboard shortcut from the config to quit DevDash. Default Control C.
 func (t *Tui) AddKQuit(key string) {
+	t.addBinding(key, "Quit")
 	t.instance.KQuit(key)
 }
 
 func (t *Tui) AddKHotReload(key string, c chan<- time.Time) {
+	t.addBinding(key, "Reload the dashboard")
 	t.instance.KHotReload(key, c)
 }
 
@@ -533,9 +851,130 @@ func (t *Tui) AddKEdit(
 	key string,
 	editDashboard func(),
 ) {
+	t.addBinding(key, "Edit the configuration")
 	t.instance.KEdit(key, editDashboard)
 }
 
+func (t *Tui) addBinding(key string, description string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.bindings = append(t.bindings, binding{key: key, description: description})
+}
+
+// AddWidgetInfo describe the widget drawn in a cell, for the help.
+// The key identifies the widget across reloads of the dashboard.
+func (t *Tui) AddWidgetInfo(id int, key string, name string, service string, options map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.widgets[id] = widgetInfo{name: name, service: service, options: options}
+	if t.failingBefore[key] {
+		t.failing[key] = true
+	}
+	if s, ok := t.staleBefore[key]; ok {
+		t.staleErrors[key] = s
+	}
+}
+
+// RecordFetch of the data of the widget drawn in a cell: when it started and how long it took.
+func (t *Tui) RecordFetch(id int, at time.Time, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.widgets[id]
+	if !ok {
+		return
+	}
+	w.fetchedAt = at
+	w.duration = duration
+	t.widgets[id] = w
+}
+
+// ToggleHelp display the keys and the information of the focused widget over the dashboard, or hide them.
+func (t *Tui) ToggleHelp() {
+	t.mu.Lock()
+	t.help = !t.help
+	t.mu.Unlock()
+
+	t.updateOverlay()
+	t.instance.Render()
+}
+
+// updateOverlay with the help if it's displayed, or with the error of the configuration if it's invalid.
+func (t *Tui) updateOverlay() {
+	id := t.instance.Focused()
+
+	t.mu.Lock()
+	title, text := "", ""
+	if t.help {
+		var focused *widgetInfo
+		if w, ok := t.widgets[id]; ok {
+			focused = &w
+		}
+		title, text = " Help ", helpText(t.bindings, focused)
+	} else if t.configError != nil {
+		title = " Invalid configuration "
+		text = t.configError.Error() + "\n\nThe dashboard is reloaded when the configuration is saved again."
+	}
+	t.mu.Unlock()
+
+	t.instance.Overlay(title, text)
+}
+
+// SetConfigError display the error of an invalid configuration over the dashboard, or remove it if the error is nil.
+func (t *Tui) SetConfigError(err error) {
+	t.mu.Lock()
+	t.configError = err
+	t.mu.Unlock()
+
+	t.updateOverlay()
+	t.instance.Render()
+}
+
+// helpText with the keys and the information of the widget focused, if any.
+func helpText(bindings []binding, focused *widgetInfo) string {
+	var b strings.Builder
+
+	b.WriteString("Keys\n\n")
+	for _, v := range bindings {
+		fmt.Fprintf(&b, "  %-14s%s\n", v.key, v.description)
+	}
+
+	b.WriteString("\nFocused widget\n\n")
+	if focused == nil {
+		b.WriteString("  No widget focused")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "  %-14s%s\n", "Name", focused.name)
+	fmt.Fprintf(&b, "  %-14s%s\n", "Service", focused.service)
+
+	lastFetch := "not fetched yet"
+	if !focused.fetchedAt.IsZero() {
+		lastFetch = fmt.Sprintf("%s (%s)", focused.fetchedAt.Format("15:04:05"), focused.duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&b, "  %-14s%s\n", "Last fetch", lastFetch)
+
+	options := make([]string, 0, len(focused.options))
+	for k, v := range focused.options {
+		options = append(options, fmt.Sprintf("%s: %s", k, v))
+	}
+	sort.Strings(options)
+	if len(options) == 0 {
+		options = append(options, "none")
+	}
+	for k, v := range options {
+		label := ""
+		if k == 0 {
+			label = "Options"
+		}
+		fmt.Fprintf(&b, "  %-14s%s\n", label, v)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 // Loop the TUI to receive events.
 func (t *Tui) Loop() {
 	t.instance.Loop()
@@ -552,5 +991,42 @@ func (t *Tui) Clean() {
 
 // Hot reload the whole TUI
 func (t *Tui) HotReload() {
+	t.resetWidgets()
 	t.instance.HotReload()
 }
+
+// KeepWidgets drawn at the next release, without adding them to the grid.
+// For example, the widgets of the projects of the pages not displayed.
+func (t *Tui) KeepWidgets(keys ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, k := range keys {
+		t.kept[k] = true
+	}
+}
+
+// ReleaseWidgets drop the last draws of the widgets which were not added or kept since the last release.
+// It should be called each time every project of the dashboard added its widgets, or kept them.
+func (t *Tui) ReleaseWidgets() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for k := range t.lastDraws {
+		if !t.kept[k] {
+			delete(t.lastDraws, k)
+		}
+	}
+	t.kept = map[string]bool{}
+}
+
+// resetWidgets before the widgets of a new build are added.
+// The state of the widgets which are not displayed anymore is dropped.
+func (t *Tui) resetWidgets() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.widgets = map[int]widgetInfo{}
+	t.failingBefore, t.failing = t.failing, map[string]bool{}
+	t.staleBefore, t.staleErrors = t.staleErrors, map[string]staleError{}
+}
@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_status(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name        string
+		expected    string
+		staleErrors map[string]staleError
+	}{
+		{
+			name:        "no stale widget",
+			expected:    "",
+			staleErrors: map[string]staleError{},
+		},
+		{
+			name:     "most recent error displayed",
+			expected: " Stale widgets: 2 | Github / github.box_stars: timeout ",
+			staleErrors: map[string]staleError{
+				"project/0/0/0/ga.box_total":     {err: errors.New("Google Analytics / ga.box_total: timeout"), at: now.Add(-time.Minute)},
+				"project/0/0/1/github.box_stars": {err: errors.New("Github / github.box_stars: timeout"), at: now},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tui := NewTUI(nil)
+			tui.staleErrors = tc.staleErrors
+
+			actual := tui.status()
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_helpText(t *testing.T) {
+	bindings := []binding{
+		{key: "C-c", description: "Quit"},
+		{key: "?", description: "Show or hide this help"},
+	}
+
+	testCases := []struct {
+		name     string
+		focused  *widgetInfo
+		expected string
+	}{
+		{
+			name:    "no widget focused",
+			focused: nil,
+			expected: "Keys\n\n" +
+				"  C-c           Quit\n" +
+				"  ?             Show or hide this help\n" +
+				"\nFocused widget\n\n" +
+				"  No widget focused",
+		},
+		{
+			name: "widget focused",
+			focused: &widgetInfo{
+				name:      "rh.box",
+				service:   "Remote host",
+				options:   map[string]string{"title": " Box ", "command": "ls"},
+				fetchedAt: time.Date(2021, 5, 3, 10, 20, 30, 0, time.UTC),
+				duration:  1500 * time.Millisecond,
+			},
+			expected: "Keys\n\n" +
+				"  C-c           Quit\n" +
+				"  ?             Show or hide this help\n" +
+				"\nFocused widget\n\n" +
+				"  Name          rh.box\n" +
+				"  Service       Remote host\n" +
+				"  Last fetch    10:20:30 (1.5s)\n" +
+				"  Options       command: ls\n" +
+				"                title:  Box ",
+		},
+		{
+			name:    "widget not fetched yet",
+			focused: &widgetInfo{name: "mon.box_availability", service: "Monitor"},
+			expected: "Keys\n\n" +
+				"  C-c           Quit\n" +
+				"  ?             Show or hide this help\n" +
+				"\nFocused widget\n\n" +
+				"  Name          mon.box_availability\n" +
+				"  Service       Monitor\n" +
+				"  Last fetch    not fetched yet\n" +
+				"  Options       none",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := helpText(bindings, tc.focused)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_statusBarText(t *testing.T) {
+	now := time.Date(2021, 5, 3, 10, 20, 30, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		bar      StatusBar
+		failing  int
+		expected string
+	}{
+		{
+			name: "next refresh",
+			bar: StatusBar{
+				Config:      "/home/user/.config/devdash/default.yml",
+				LastRefresh: now.Add(-time.Minute),
+				NextRefresh: now.Add(90 * time.Second),
+			},
+			failing:  2,
+			expected: " default.yml | Last refresh 10:19:30 | Next refresh in 1m30s | Widgets in error: 2 ",
+		},
+		{
+			name: "refresh overdue",
+			bar: StatusBar{
+				Config:      "default.yml",
+				LastRefresh: now.Add(-time.Minute),
+				NextRefresh: now.Add(-time.Second),
+			},
+			expected: " default.yml | Last refresh 10:19:30 | Next refresh in 0s | Widgets in error: 0 ",
+		},
+		{
+			name: "auto refresh paused",
+			bar: StatusBar{
+				Config: "default.yml",
+				Paused: true,
+			},
+			expected: " default.yml | Last refresh never | Auto refresh paused | Widgets in error: 0 ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := statusBarText(tc.bar, tc.failing, now)
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_resetWidgets(t *testing.T) {
+	tui := NewTUI(nil)
+	tui.failing = map[string]bool{
+		"project/0/0/0/ga.box_total":     true,
+		"project/0/0/1/github.box_stars": true,
+	}
+	tui.staleErrors = map[string]staleError{
+		"project/0/0/0/ga.box_total":     {err: errors.New("timeout")},
+		"project/0/0/1/github.box_stars": {err: errors.New("timeout")},
+	}
+
+	tui.resetWidgets()
+	tui.AddWidgetInfo(1, "project/0/0/1/github.box_stars", "github.box_stars", "Github", nil)
+	tui.AddWidgetInfo(2, "project/0/0/2/mon.box_availability", "mon.box_availability", "Monitor", nil)
+
+	expected := map[string]bool{"project/0/0/1/github.box_stars": true}
+	if !reflect.DeepEqual(expected, tui.failing) {
+		t.Errorf("Expected %v, actual %v", expected, tui.failing)
+	}
+
+	if _, ok := tui.staleErrors["project/0/0/1/github.box_stars"]; !ok || len(tui.staleErrors) != 1 {
+		t.Errorf("Expected %v, actual %v", "project/0/0/1/github.box_stars", tui.staleErrors)
+	}
+}
+
+func Test_ReleaseWidgets(t *testing.T) {
+	draw := lastDraw{draw: func() error { return nil }}
+	tui := NewTUI(nil)
+	tui.lastDraws = map[string]lastDraw{
+		"project/0/0/0/ga.box_total":          draw,
+		"project/0/0/1/github.box_stars":      draw,
+		"other/0/0/0/mon.box_availability":    draw,
+		"removed/0/0/0/travis.table_builds":   draw,
+		"project/0/0/2/github.table_branches": draw,
+	}
+
+	tui.kept["project/0/0/0/ga.box_total"] = true
+	tui.kept["project/0/0/1/github.box_stars"] = true
+	tui.KeepWidgets("other/0/0/0/mon.box_availability")
+	tui.ReleaseWidgets()
+
+	expected := []string{
+		"other/0/0/0/mon.box_availability",
+		"project/0/0/0/ga.box_total",
+		"project/0/0/1/github.box_stars",
+	}
+	actual := []string{}
+	for k := range tui.lastDraws {
+		actual = append(actual, k)
+	}
+	sort.Strings(actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+
+	if len(tui.kept) != 0 {
+		t.Errorf("Expected no widget kept after the release, actual %v", tui.kept)
+	}
+}
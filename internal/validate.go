@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigProblem of a widget or a service in the configuration of a dashboard.
+type ConfigProblem struct {
+	// Field with the problem, relative to the widget or the service (for example "options.height").
+	Field   string
+	Message string
+}
+
+// CheckWidget of a project: its name, its service and its options.
+// The services are the ones configured in the project, indexed by their config keys.
+func CheckWidget(w Widget, services map[string]map[string]string) []ConfigProblem {
+	problems := []ConfigProblem{}
+
+	parts := strings.SplitN(w.Name, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return append(problems, ConfigProblem{Field: "name", Message: "widget " + w.Name + " should be <service>.<widget>"})
+	}
+
+	serviceType, instance := splitInstance(strings.ToLower(parts[0]))
+	def, err := lookupService(serviceType)
+	if err != nil {
+		return append(problems, ConfigProblem{Field: "name", Message: "service " + serviceType + " of widget " + w.Name + " doesn't exist"})
+	}
+
+	schema, ok := def.widget(parts[1])
+	if !ok {
+		problems = append(problems, ConfigProblem{Field: "name", Message: "widget " + w.Name + " doesn't exist for service " + def.Name})
+	}
+
+	if def.ConfigKey != "" && !isConfigured(def, instance, services) {
+		problems = append(problems, ConfigProblem{
+			Field:   "name",
+			Message: "widget " + w.Name + " needs the service " + serviceKey(def, instance) + " in the services of the project",
+		})
+	}
+
+	if ok {
+		problems = append(problems, checkOptions(w, schema)...)
+	}
+
+	return problems
+}
+
+// checkOptions of a widget against its schema: every option needs to exist and to have the right type.
+func checkOptions(w Widget, schema WidgetSchema) []ConfigProblem {
+	problems := []ConfigProblem{}
+	for _, k := range sortedKeys(w.Options) {
+		o, ok := lookupOption(schema.Options, k)
+		if !ok {
+			problems = append(problems, ConfigProblem{Field: "options." + k, Message: "option " + k + " doesn't exist for widget " + w.Name})
+			continue
+		}
+		if err := o.check(w.Options[k]); err != nil {
+			problems = append(problems, ConfigProblem{Field: "options." + k, Message: err.Error()})
+		}
+	}
+
+	return problems
+}
+
+// checkWidgetOptions against the schema of the widget, before fetching its data.
+// The widgets which don't exist are not checked.
+func checkWidgetOptions(w Widget) error {
+	schema, ok := w.schema()
+	if !ok {
+		return nil
+	}
+
+	problems := checkOptions(w, schema)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(problems))
+	for _, p := range problems {
+		messages = append(messages, p.Message)
+	}
+
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+// CheckService configured with a key (for example "remote_host:web1") in the "services" section of a project.
+func CheckService(key string, options map[string]string) []ConfigProblem {
+	configKey, _ := splitInstance(strings.ToLower(key))
+	for _, def := range ServiceDefinitions() {
+		if def.ConfigKey != configKey {
+			continue
+		}
+
+		problems := []ConfigProblem{}
+		for _, k := range sortedKeys(options) {
+			if !hasServiceOption(def, k) {
+				problems = append(problems, ConfigProblem{Field: k, Message: "option " + k + " doesn't exist for service " + def.Name})
+			}
+		}
+		return problems
+	}
+
+	return []ConfigProblem{{Message: "service " + key + " doesn't exist"}}
+}
+
+// CheckOption of a theme or of the name of a project: the option needs to be accepted by a widget or by the
+// title of a project, and its value needs to have the right type.
+func CheckOption(name string, value string) error {
+	o, ok := lookupOption(themeOptions(), name)
+	if !ok {
+		return errors.Errorf("option %s doesn't exist", name)
+	}
+
+	return o.check(value)
+}
+
+// themeOptions are every option of every widget, and the options of the title of a project.
+func themeOptions() []OptionSchema {
+	options := []OptionSchema{}
+	for _, o := range projectTitleOptions {
+		if _, ok := lookupOption(options, o.Name); !ok {
+			options = append(options, o)
+		}
+	}
+
+	for _, def := range ServiceDefinitions() {
+		for _, w := range def.Widgets {
+			for _, o := range w.Options {
+				if _, ok := lookupOption(options, o.Name); !ok {
+					options = append(options, o)
+				}
+			}
+		}
+	}
+
+	return options
+}
+
+func isConfigured(def ServiceDefinition, instance string, services map[string]map[string]string) bool {
+	for k := range services {
+		configKey, i := splitInstance(strings.ToLower(k))
+		if configKey == def.ConfigKey && i == instance {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serviceKey(def ServiceDefinition, instance string) string {
+	if instance == "" {
+		return def.ConfigKey
+	}
+
+	return def.ConfigKey + instanceSeparator + instance
+}
+
+func hasServiceOption(def ServiceDefinition, name string) bool {
+	for _, o := range def.Options {
+		if o.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_CheckOption(t *testing.T) {
+	testCases := []struct {
+		name    string
+		option  string
+		value   string
+		wantErr bool
+	}{
+		{name: "string", option: optionTitle, value: " Title "},
+		{name: "int", option: optionHeight, value: "10"},
+		{name: "wrong int", option: optionHeight, value: "tall", wantErr: true},
+		{name: "bool", option: optionBold, value: "true"},
+		{name: "wrong bool", option: optionBold, value: "yes please", wantErr: true},
+		{name: "color", option: optionColor, value: "yellow"},
+		{name: "wrong color", option: optionColor, value: "purple", wantErr: true},
+		{name: "action", option: optionAction, value: "copy"},
+		{name: "wrong action", option: optionAction, value: "print", wantErr: true},
+		{name: "unknown option", option: "heigth", value: "3", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckOption(tc.option, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, actual %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_CheckWidget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		widget   Widget
+		services map[string]map[string]string
+		expected []string
+	}{
+		{
+			name:     "valid widget",
+			widget:   Widget{Name: "lh.box_uptime"},
+			expected: []string{},
+		},
+		{
+			name:     "unknown service",
+			widget:   Widget{Name: "gitub.box_stars"},
+			expected: []string{"name"},
+		},
+		{
+			name:     "unknown widget",
+			widget:   Widget{Name: "lh.box_uptim"},
+			expected: []string{"name"},
+		},
+		{
+			name:     "service not configured",
+			widget:   Widget{Name: "rh:web1.box_load"},
+			services: map[string]map[string]string{"remote_host": {"address": "web1"}},
+			expected: []string{"name"},
+		},
+		{
+			name:     "service instance configured",
+			widget:   Widget{Name: "rh:web1.box_load"},
+			services: map[string]map[string]string{"remote_host:web1": {"address": "web1"}},
+			expected: []string{},
+		},
+		{
+			name:     "wrong options",
+			widget:   Widget{Name: "lh.table", Options: map[string]string{optionHeight: "tall", "heigth": "3"}},
+			expected: []string{"options.height", "options.heigth"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []string{}
+			for _, p := range CheckWidget(tc.widget, tc.services) {
+				actual = append(actual, p.Field)
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_checkWidgetOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		widget   Widget
+		expected string
+	}{
+		{
+			name:   "valid options",
+			widget: Widget{Name: "lh.table", Options: map[string]string{optionHeight: "10", optionCommand: "ls"}},
+		},
+		{
+			name:     "unknown option",
+			widget:   Widget{Name: "lh.box_uptime", Options: map[string]string{optionRowLimit: "10"}},
+			expected: "option row_limit doesn't exist for widget lh.box_uptime",
+		},
+		{
+			name:     "wrong type",
+			widget:   Widget{Name: "lh.box_uptime", Options: map[string]string{optionBold: "yes"}},
+			expected: `option bold should be of type bool, not "yes"`,
+		},
+		{
+			name:   "unknown widget",
+			widget: Widget{Name: "lh.box_uptim", Options: map[string]string{optionRowLimit: "10"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ""
+			if err := checkWidgetOptions(tc.widget); err != nil {
+				actual = err.Error()
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -56,6 +56,10 @@ type Widget struct {
 	Size    string            `mapstructures:"size"`
 	Options map[string]string `mapstructures:"options"`
 	Theme   string            `mapstructures:"theme"`
+	// Refresh interval of the widget in seconds, independently of the refresh of the whole dashboard.
+	Refresh int64 `mapstructures:"refresh"`
+	// Timeout to fetch the data of the widget in seconds. Override the timeout of the dashboard.
+	Timeout int64 `mapstructures:"timeout"`
 }
 
 func (w *Widget) typeID() string {
@@ -63,6 +67,21 @@ func (w *Widget) typeID() string {
 	return strings.Split(n, "_")[0]
 }
 
+// serviceID of the widget, including the name of the service instance if any (for example "rh:web1").
 func (w *Widget) serviceID() string {
-	return strings.Split(w.Name, ".")[0]
+	return strings.ToLower(strings.Split(w.Name, ".")[0])
+}
+
+// serviceType is the ID of the registered service, without instance (for example "rh").
+func (w *Widget) serviceType() string {
+	return strings.Split(w.serviceID(), instanceSeparator)[0]
+}
+
+// withoutInstance return the widget as the service expects it (for example "rh.box_uptime" for "rh:web1.box_uptime").
+func (w Widget) withoutInstance() Widget {
+	if i := strings.Index(w.Name, "."); i != -1 {
+		w.Name = w.serviceType() + w.Name[i:]
+	}
+
+	return w
 }
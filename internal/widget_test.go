@@ -41,6 +41,13 @@ func Test_serviceID(t *testing.T) {
 				Name: "ga.bar_chart",
 			},
 		},
+		{
+			name:     "service instance",
+			expected: "rh:web1",
+			widget: Widget{
+				Name: "rh:Web1.box_uptime",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -53,3 +60,36 @@ func Test_serviceID(t *testing.T) {
 		})
 	}
 }
+
+func Test_withoutInstance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		widget   Widget
+	}{
+		{
+			name:     "without instance",
+			expected: "ga.bar_chart",
+			widget: Widget{
+				Name: "ga.bar_chart",
+			},
+		},
+		{
+			name:     "with instance",
+			expected: "rh.box_uptime",
+			widget: Widget{
+				Name: "rh:web1.box_uptime",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.widget.withoutInstance()
+
+			if actual.Name != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual.Name)
+			}
+		})
+	}
+}